@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/nicheinc/mock/internal/diff"
+)
+
+// staleFiles compares the rendered mocks, keyed by output path, with the
+// existing files at those paths. It returns, in sorted order, the paths of the
+// files whose contents differ and of those that don't exist yet.
+func staleFiles(rendered map[string][]byte) (stale, missing []string, err error) {
+	for _, outputPath := range slices.Sorted(maps.Keys(rendered)) {
+		existing, readErr := os.ReadFile(outputPath)
+		switch {
+		case errors.Is(readErr, fs.ErrNotExist):
+			missing = append(missing, outputPath)
+		case readErr != nil:
+			return nil, nil, fmt.Errorf("reading %s: %w", outputPath, readErr)
+		case !bytes.Equal(existing, rendered[outputPath]):
+			stale = append(stale, outputPath)
+		}
+	}
+	return stale, missing, nil
+}
+
+// diffFile returns a unified diff between the existing file at the given path,
+// which may not exist, and its rendered contents. Nil contents mean the file is
+// to be deleted.
+func diffFile(outputPath string, rendered []byte) ([]byte, error) {
+	// As in git, relative paths are prefixed to distinguish the old and new
+	// versions.
+	oldName, newName := displayPath(outputPath), displayPath(outputPath)
+	if !filepath.IsAbs(oldName) {
+		oldName = "a/" + filepath.ToSlash(oldName)
+		newName = "b/" + filepath.ToSlash(newName)
+	}
+	if rendered == nil {
+		newName = "/dev/null"
+	}
+	existing, readErr := os.ReadFile(outputPath)
+	if errors.Is(readErr, fs.ErrNotExist) {
+		oldName = "/dev/null"
+	} else if readErr != nil {
+		return nil, fmt.Errorf("reading %s: %w", outputPath, readErr)
+	}
+	return diff.Unified(oldName, newName, existing, rendered), nil
+}
+
+// displayPath returns the given path relative to the working directory, if
+// it's within it, for use in messages.
+func displayPath(path string) string {
+	wd, wdErr := os.Getwd()
+	if wdErr != nil {
+		return path
+	}
+	rel, relErr := filepath.Rel(wd, path)
+	if relErr != nil || !filepath.IsLocal(rel) {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nicheinc/expect"
+)
+
+func TestStaleFiles(t *testing.T) {
+	var (
+		dir     = t.TempDir()
+		current = filepath.Join(dir, "current_mock.go")
+		stale   = filepath.Join(dir, "stale_mock.go")
+		missing = filepath.Join(dir, "missing_mock.go")
+	)
+	if writeErr := os.WriteFile(current, []byte("package current\n"), 0o644); writeErr != nil {
+		t.Fatal(writeErr)
+	}
+	if writeErr := os.WriteFile(stale, []byte("package old\n"), 0o644); writeErr != nil {
+		t.Fatal(writeErr)
+	}
+
+	actualStale, actualMissing, err := staleFiles(map[string][]byte{
+		current: []byte("package current\n"),
+		stale:   []byte("package stale\n"),
+		missing: []byte("package missing\n"),
+	})
+	expect.ErrorNil(t, err)
+	expect.Equal(t, actualStale, []string{stale})
+	expect.Equal(t, actualMissing, []string{missing})
+}
@@ -0,0 +1,18 @@
+// Command mockcheck runs the mockcheck analyzer, which checks go:mock directives
+// and the mocks generated from them, on the packages given as arguments:
+//
+//	mockcheck ./...
+//
+// It can also be run by go vet:
+//
+//	go vet -vettool=$(which mockcheck) ./...
+package main
+
+import (
+	"github.com/nicheinc/mock/mockcheck"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(mockcheck.Analyzer)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"github.com/nicheinc/mock/iface"
+	"golang.org/x/tools/go/packages"
+)
+
+// overrideOptions overrides the options for interfaces declared in the given
+// package with the command line flags, which take precedence over
+// configuration files.
+func (c config) overrideOptions(_ *packages.Package, options *iface.Options) {
+	if c.setFlags["o"] {
+		options.OutputFile = c.outputFile
+	}
+	if c.setFlags["out-dir"] {
+		options.OutputDir = c.outputDir
+	}
+	if c.setFlags["test"] {
+		options.TestOutput = c.testOutput
+	}
+	if c.setFlags["tags"] {
+		options.Tags = c.tags
+	}
+	if c.setFlags["lenient"] {
+		options.Lenient = c.lenient
+	}
+	if c.setFlags["only"] {
+		options.OnlyMethods = c.only
+	}
+	if c.setFlags["exclude"] {
+		options.ExcludeMethods = c.exclude
+	}
+	if c.setFlags["name"] {
+		options.MockName = c.mockName
+	}
+	if c.setFlags["name-pattern"] {
+		options.NamePattern = c.pattern
+	}
+	if c.setFlags["style"] {
+		options.Style = c.style
+	}
+	if c.setFlags["template"] {
+		options.Template = c.template
+	}
+}
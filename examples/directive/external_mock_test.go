@@ -0,0 +1,34 @@
+// Code generated by mock from external_test.go. DO NOT EDIT.
+
+package directive_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/nicheinc/mock/examples/directive"
+)
+
+// ExternalMock is a mock implementation of the External
+// interface.
+type ExternalMock struct {
+	T             *testing.T
+	ExampleStub   func() directive.Example
+	ExampleCalled int32
+}
+
+// Verify that *ExternalMock implements External.
+var _ External = &ExternalMock{}
+
+// Example is a stub for the External.Example
+// method that records the number of times it has been called.
+func (m *ExternalMock) Example() directive.Example {
+	atomic.AddInt32(&m.ExampleCalled, 1)
+	if m.ExampleStub == nil {
+		if m.T != nil {
+			m.T.Error("ExampleStub is nil")
+		}
+		panic("Example unimplemented")
+	}
+	return m.ExampleStub()
+}
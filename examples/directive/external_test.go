@@ -0,0 +1,11 @@
+package directive_test
+
+import "github.com/nicheinc/mock/examples/directive"
+
+// External is declared in the external test package directive_test, and so is
+// its mock.
+//
+//go:mock
+type External interface {
+	Example() directive.Example
+}
@@ -0,0 +1,12 @@
+package directive
+
+// Lenient demonstrates go:mock directive options, which override the
+// corresponding command line flags for a single interface. Here, the -lenient
+// option makes LenientMock's methods return zero values when their stubs are
+// nil, and the -o option specifies the output file.
+//
+//go:mock -lenient -o lenient_mock.go
+type Lenient interface {
+	Get(key string) (value string, ok bool)
+	Set(key, value string)
+}
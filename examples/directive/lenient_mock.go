@@ -0,0 +1,41 @@
+// Code generated by mock from lenient.go. DO NOT EDIT.
+
+package directive
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+// LenientMock is a mock implementation of the Lenient
+// interface.
+type LenientMock struct {
+	T         *testing.T
+	GetStub   func(key string) (value string, ok bool)
+	GetCalled int32
+	SetStub   func(key string, value string)
+	SetCalled int32
+}
+
+// Verify that *LenientMock implements Lenient.
+var _ Lenient = &LenientMock{}
+
+// Get is a stub for the Lenient.Get
+// method that records the number of times it has been called.
+func (m *LenientMock) Get(key string) (value string, ok bool) {
+	atomic.AddInt32(&m.GetCalled, 1)
+	if m.GetStub == nil {
+		return *new(string), *new(bool)
+	}
+	return m.GetStub(key)
+}
+
+// Set is a stub for the Lenient.Set
+// method that records the number of times it has been called.
+func (m *LenientMock) Set(key string, value string) {
+	atomic.AddInt32(&m.SetCalled, 1)
+	if m.SetStub == nil {
+		return
+	}
+	m.SetStub(key, value)
+}
@@ -0,0 +1,51 @@
+// Code generated by mock from ../remote.go. DO NOT EDIT.
+
+package mocks
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nicheinc/mock/examples/directive"
+	"github.com/nicheinc/mock/examples/directive/internal"
+)
+
+// RemoteMock is a mock implementation of the Remote
+// interface.
+type RemoteMock struct {
+	T           *testing.T
+	FetchStub   func(ctx context.Context, id int) (internal.Internal, error)
+	FetchCalled int32
+	SelfStub    func() directive.Remote
+	SelfCalled  int32
+}
+
+// Verify that *RemoteMock implements directive.Remote.
+var _ directive.Remote = &RemoteMock{}
+
+// Fetch is a stub for the Remote.Fetch
+// method that records the number of times it has been called.
+func (m *RemoteMock) Fetch(ctx context.Context, id int) (internal.Internal, error) {
+	atomic.AddInt32(&m.FetchCalled, 1)
+	if m.FetchStub == nil {
+		if m.T != nil {
+			m.T.Error("FetchStub is nil")
+		}
+		panic("Fetch unimplemented")
+	}
+	return m.FetchStub(ctx, id)
+}
+
+// Self is a stub for the Remote.Self
+// method that records the number of times it has been called.
+func (m *RemoteMock) Self() directive.Remote {
+	atomic.AddInt32(&m.SelfCalled, 1)
+	if m.SelfStub == nil {
+		if m.T != nil {
+			m.T.Error("SelfStub is nil")
+		}
+		panic("Self unimplemented")
+	}
+	return m.SelfStub()
+}
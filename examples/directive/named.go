@@ -0,0 +1,10 @@
+package directive
+
+// Named demonstrates customizing the name of the mock type. The -name option
+// specifies the name outright, and the -name-pattern option (which can also be
+// set for a whole module in mock.json) derives it from the interface's name.
+//
+//go:mock -name-pattern Fake%s
+type Named interface {
+	Name() string
+}
@@ -0,0 +1,32 @@
+// Code generated by mock from named.go. DO NOT EDIT.
+
+package directive
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+// FakeNamed is a mock implementation of the Named
+// interface.
+type FakeNamed struct {
+	T          *testing.T
+	NameStub   func() string
+	NameCalled int32
+}
+
+// Verify that *FakeNamed implements Named.
+var _ Named = &FakeNamed{}
+
+// Name is a stub for the Named.Name
+// method that records the number of times it has been called.
+func (m *FakeNamed) Name() string {
+	atomic.AddInt32(&m.NameCalled, 1)
+	if m.NameStub == nil {
+		if m.T != nil {
+			m.T.Error("NameStub is nil")
+		}
+		panic("Name unimplemented")
+	}
+	return m.NameStub()
+}
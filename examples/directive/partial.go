@@ -0,0 +1,13 @@
+package directive
+
+import "io"
+
+// Partial demonstrates mocking a subset of an interface's methods. PartialMock
+// only has a stub for Get, and embeds a Partial value to provide the rest.
+//
+//go:mock -only Get
+type Partial interface {
+	Get(key string) (string, error)
+	Set(key, value string) error
+	io.Closer
+}
@@ -0,0 +1,35 @@
+// Code generated by mock from partial.go. DO NOT EDIT.
+
+package directive
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+// PartialMock is a mock implementation of the Partial
+// interface.
+type PartialMock struct {
+	// Partial provides the methods without stubs below. Calling
+	// them panics unless it's set.
+	Partial
+	T         *testing.T
+	GetStub   func(key string) (string, error)
+	GetCalled int32
+}
+
+// Verify that *PartialMock implements Partial.
+var _ Partial = &PartialMock{}
+
+// Get is a stub for the Partial.Get
+// method that records the number of times it has been called.
+func (m *PartialMock) Get(key string) (string, error) {
+	atomic.AddInt32(&m.GetCalled, 1)
+	if m.GetStub == nil {
+		if m.T != nil {
+			m.T.Error("GetStub is nil")
+		}
+		panic("Get unimplemented")
+	}
+	return m.GetStub(key)
+}
@@ -0,0 +1,18 @@
+package directive
+
+import (
+	"context"
+
+	"github.com/nicheinc/mock/examples/directive/internal"
+)
+
+// Remote demonstrates generating a mock into a different package. Because the
+// go:mock directive's output file is in the mocks subdirectory, RemoteMock
+// belongs to package mocks, which must import this package (and can, in this
+// case, import its internal package too).
+//
+//go:mock mocks/remote_mock.go
+type Remote interface {
+	Fetch(ctx context.Context, id int) (internal.Internal, error)
+	Self() Remote
+}
@@ -0,0 +1,33 @@
+// Code generated by mock from testonly_test.go. DO NOT EDIT.
+
+package directive
+
+import (
+	"io"
+	"sync/atomic"
+	"testing"
+)
+
+// TestOnlyMock is a mock implementation of the TestOnly
+// interface.
+type TestOnlyMock struct {
+	T          *testing.T
+	OpenStub   func(name string) (io.ReadCloser, error)
+	OpenCalled int32
+}
+
+// Verify that *TestOnlyMock implements TestOnly.
+var _ TestOnly = &TestOnlyMock{}
+
+// Open is a stub for the TestOnly.Open
+// method that records the number of times it has been called.
+func (m *TestOnlyMock) Open(name string) (io.ReadCloser, error) {
+	atomic.AddInt32(&m.OpenCalled, 1)
+	if m.OpenStub == nil {
+		if m.T != nil {
+			m.T.Error("OpenStub is nil")
+		}
+		panic("Open unimplemented")
+	}
+	return m.OpenStub(name)
+}
@@ -0,0 +1,11 @@
+package directive
+
+import "io"
+
+// TestOnly is declared in a test file, so it's only visible to the package's
+// tests. Its mock is written to testonly_mock_test.go by default.
+//
+//go:mock
+type TestOnly interface {
+	Open(name string) (io.ReadCloser, error)
+}
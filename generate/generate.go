@@ -0,0 +1,263 @@
+// Package generate generates mocks of the interfaces annotated with go:mock
+// directives, as the mock command does, so that other tools can generate mocks
+// in-process.
+//
+// Generate loads the packages, finds their annotated interfaces, and renders
+// their mock files in memory, leaving it to the caller to write them, e.g. with
+// WriteFiles. Config's methods perform these steps individually, for callers
+// that need to inspect or alter the mock files in between.
+package generate
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"runtime"
+	"slices"
+
+	"github.com/nicheinc/mock/iface"
+	"github.com/nicheinc/mock/internal/configfile"
+	"github.com/nicheinc/mock/render"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/tools/go/packages"
+)
+
+// LoadMode is the information about packages that Config's Load function must
+// provide, along with their test variants.
+const LoadMode = packages.LoadSyntax | packages.NeedModule
+
+// Config configures the generation of mocks. The zero value generates the
+// mocks of the interfaces in the package in the working directory.
+type Config struct {
+	// Dir is the directory in which to load packages, or the working directory
+	// if empty.
+	Dir string
+	// Patterns are the patterns of the packages to search for interfaces, or
+	// "." if empty.
+	Patterns []string
+	// Load, if non-nil, loads the packages matching the given patterns in the
+	// given directory in place of packages.Load, with at least the information
+	// in LoadMode and including test variants.
+	Load func(ctx context.Context, dir string, patterns ...string) ([]*packages.Package, error)
+
+	// Style is the built-in template for mocks, unless overridden by
+	// configuration files or directives. Empty means render.DefaultStyle.
+	Style string
+	// Options, if non-nil, overrides the options for interfaces declared in
+	// the given package, which start with those of its module's mock.json
+	// configuration file. The interfaces' go:mock directives may override them
+	// in turn.
+	Options func(pkg *packages.Package, options *iface.Options)
+	// Templates, if non-nil, caches the templates loaded by each method, which
+	// otherwise load them anew.
+	Templates *render.Cache
+	// Jobs is the maximum number of mock files to construct or render
+	// concurrently, or runtime.GOMAXPROCS(0) if less than 1.
+	Jobs int
+	// Version is the version of mock recorded in the mock files' headers, if
+	// nonempty.
+	Version string
+}
+
+// Generate generates the mocks of the interfaces annotated with go:mock
+// directives in the packages matching the configuration's patterns, returning
+// the contents of the mock files keyed by output path. If any of the mocks
+// can't be generated, it returns an error joining all the errors found, and no
+// files.
+func Generate(ctx context.Context, config Config) (map[string][]byte, error) {
+	pkgs, loadErr := config.LoadPackages(ctx)
+	if loadErr != nil {
+		return nil, fmt.Errorf("loading packages: %w", loadErr)
+	}
+	filesByPath, getErr := config.Interfaces(pkgs)
+	if getErr != nil {
+		return nil, getErr
+	}
+	if checkErr := iface.CheckMockNames(pkgs, filesByPath, nil, config.templates().Declarations); checkErr != nil {
+		return nil, fmt.Errorf("naming mocks: %w", checkErr)
+	}
+	return config.Render(ctx, filesByPath)
+}
+
+// LoadPackages loads the packages matching the configuration's patterns, along
+// with their test variants.
+func (c Config) LoadPackages(ctx context.Context) ([]*packages.Package, error) {
+	patterns := c.Patterns
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	var (
+		pkgs    []*packages.Package
+		loadErr error
+	)
+	if c.Load != nil {
+		pkgs, loadErr = c.Load(ctx, c.Dir, patterns...)
+	} else {
+		pkgs, loadErr = packages.Load(&packages.Config{Context: ctx, Dir: c.Dir, Mode: LoadMode, Tests: true}, patterns...)
+	}
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	return pkgs, nil
+}
+
+// Searched returns the given packages, except those whose modules'
+// configuration files exclude them from the search for interfaces.
+func (c Config) Searched(pkgs []*packages.Package) ([]*packages.Package, error) {
+	fileConfigs, configErr := readFileConfigs(pkgs)
+	if configErr != nil {
+		return nil, configErr
+	}
+	return searched(fileConfigs, pkgs), nil
+}
+
+// Interfaces searches the given packages, except those excluded by their
+// modules' configuration files, for interfaces annotated with go:mock
+// directives, returning their mock files keyed by output path. Errors are
+// joined as by iface.GetAllInterfaces.
+func (c Config) Interfaces(pkgs []*packages.Package) (map[string]iface.File, error) {
+	fileConfigs, configErr := readFileConfigs(pkgs)
+	if configErr != nil {
+		return nil, configErr
+	}
+	templates := c.templates()
+	return iface.GetAllInterfaces(searched(fileConfigs, pkgs), func(pkg *packages.Package) iface.Options {
+		return c.options(fileConfigs, templates, pkg)
+	}, c.jobs())
+}
+
+// Interface searches the given packages, which must comprise a single package
+// along with its test variants, for the named interface, returning its mock
+// file. Its options are those of the first package.
+func (c Config) Interface(pkgs []*packages.Package, ifaceName string) (iface.File, error) {
+	if len(pkgs) < 1 {
+		return iface.File{}, fmt.Errorf("interface %s not found: no packages", ifaceName)
+	}
+	fileConfigs, configErr := readFileConfigs(pkgs)
+	if configErr != nil {
+		return iface.File{}, configErr
+	}
+	return iface.GetInterface(pkgs, ifaceName, c.options(fileConfigs, c.templates(), pkgs[0]))
+}
+
+// Render renders the given mock files concurrently, each with its own
+// template, returning their contents keyed by output path. If any fail, it
+// returns their errors joined in the order of their output paths.
+func (c Config) Render(ctx context.Context, filesByPath map[string]iface.File) (map[string][]byte, error) {
+	var (
+		templates   = c.templates()
+		outputPaths = slices.Sorted(maps.Keys(filesByPath))
+		contents    = make([][]byte, len(outputPaths))
+		renderErrs  = make([]error, len(outputPaths))
+		group       errgroup.Group
+	)
+	group.SetLimit(c.jobs())
+	for i, outputPath := range outputPaths {
+		group.Go(func() error {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				renderErrs[i] = ctxErr
+				return nil
+			}
+			file := filesByPath[outputPath]
+			tmpl, loadErr := templates.Load(file.Style, file.Template)
+			if loadErr != nil {
+				renderErrs[i] = fmt.Errorf("loading template for %s: %w", outputPath, loadErr)
+				return nil
+			}
+			file.Version = c.Version
+			contents[i], renderErrs[i] = tmpl.Render(outputPath, file)
+			return nil
+		})
+	}
+	group.Wait()
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if joinedErr := errors.Join(renderErrs...); joinedErr != nil {
+		return nil, joinedErr
+	}
+	rendered := map[string][]byte{}
+	for i, outputPath := range outputPaths {
+		rendered[outputPath] = contents[i]
+	}
+	return rendered, nil
+}
+
+// options returns the options for interfaces declared in the given package. In
+// increasing order of precedence, they come from the configuration's style, the
+// module's configuration file, the configuration for the package's directory,
+// and the configuration's Options function.
+func (c Config) options(fileConfigs map[string]configfile.File, templates *render.Cache, pkg *packages.Package) iface.Options {
+	// Resolve the default style up front, so that directives naming it aren't
+	// taken to differ from those that don't.
+	options := iface.Options{Style: cmp.Or(c.Style, render.DefaultStyle), TemplateImports: templates.Imports}
+	if dir, inModule := moduleDir(pkg); inModule {
+		fileConfigs[pkg.Module.Dir].Apply(pkg.Module.Dir, dir, &options)
+	}
+	if c.Options != nil {
+		c.Options(pkg, &options)
+	}
+	return options
+}
+
+// templates returns the configuration's template cache, or a new one if it
+// doesn't have one.
+func (c Config) templates() *render.Cache {
+	if c.Templates != nil {
+		return c.Templates
+	}
+	return render.NewCache()
+}
+
+// jobs returns the maximum number of mock files to process concurrently.
+func (c Config) jobs() int {
+	if c.Jobs < 1 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return c.Jobs
+}
+
+// readFileConfigs reads the configuration files of the given packages' modules,
+// keyed by module directory.
+func readFileConfigs(pkgs []*packages.Package) (map[string]configfile.File, error) {
+	fileConfigs := map[string]configfile.File{}
+	for _, pkg := range pkgs {
+		if pkg.Module == nil || pkg.Module.Dir == "" {
+			continue
+		}
+		if _, loaded := fileConfigs[pkg.Module.Dir]; !loaded {
+			fileConfig, readErr := configfile.Read(pkg.Module.Dir)
+			if readErr != nil {
+				return nil, fmt.Errorf("loading configuration file: %w", readErr)
+			}
+			fileConfigs[pkg.Module.Dir] = fileConfig
+		}
+	}
+	return fileConfigs, nil
+}
+
+// searched returns the given packages, except those excluded by the given
+// configuration files.
+func searched(fileConfigs map[string]configfile.File, pkgs []*packages.Package) []*packages.Package {
+	return slices.DeleteFunc(slices.Clone(pkgs), func(pkg *packages.Package) bool {
+		dir, inModule := moduleDir(pkg)
+		return inModule && fileConfigs[pkg.Module.Dir].Excluded(dir)
+	})
+}
+
+// moduleDir returns the given package's module-relative directory, or false if
+// it's not in a module.
+func moduleDir(pkg *packages.Package) (string, bool) {
+	if pkg.Module == nil || pkg.Module.Dir == "" {
+		return "", false
+	}
+	rel, relErr := filepath.Rel(pkg.Module.Dir, pkg.Dir)
+	if relErr != nil || !filepath.IsLocal(rel) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
@@ -0,0 +1,185 @@
+package generate
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nicheinc/expect"
+	"github.com/nicheinc/mock/iface"
+	"github.com/nicheinc/mock/internal/testmodule"
+	"golang.org/x/tools/go/packages"
+)
+
+// loadFromSource loads packages by type-checking their dependencies from
+// source, rather than relying on export data.
+func loadFromSource(ctx context.Context, dir string, patterns ...string) ([]*packages.Package, error) {
+	return packages.Load(&packages.Config{Context: ctx, Dir: dir, Mode: LoadMode | packages.NeedDeps, Tests: true}, patterns...)
+}
+
+func TestInterfaces(t *testing.T) {
+	type testCase struct {
+		files          map[string]string
+		config         Config
+		expectedStyles map[string]string
+		errorCheck     expect.ErrorCheck
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			config := testCase.config
+			config.Dir = testmodule.Write(t, testCase.files)
+			config.Load = loadFromSource
+			config.Patterns = []string{"./..."}
+			pkgs, loadErr := config.LoadPackages(context.Background())
+			expect.ErrorNil(t, loadErr)
+
+			filesByPath, getErr := config.Interfaces(pkgs)
+			testCase.errorCheck(t, getErr)
+			actual := map[string]string{}
+			for outputPath, file := range filesByPath {
+				rel, relErr := filepath.Rel(config.Dir, outputPath)
+				expect.ErrorNil(t, relErr)
+				actual[filepath.ToSlash(rel)] = file.Style
+			}
+			if getErr != nil {
+				actual = nil
+			}
+			expect.Equal(t, actual, testCase.expectedStyles)
+		})
+	}
+
+	const store = `package store
+
+//go:mock shared_mock.go
+type Getter interface{ Get() int }
+
+//go:mock -style %s shared_mock.go
+type Putter interface{ Put(int) }
+`
+	run("DefaultStyle", testCase{
+		files: map[string]string{
+			"store/store.go": strings.Replace(store, "%s", "minimal", 1),
+		},
+		expectedStyles: map[string]string{"store/shared_mock.go": "minimal"},
+		errorCheck:     expect.ErrorNil,
+	})
+	run("ConfiguredStyle", testCase{
+		files: map[string]string{
+			"store/store.go": strings.Replace(store, "%s", "fluent", 1),
+		},
+		config:         Config{Style: "fluent"},
+		expectedStyles: map[string]string{"store/shared_mock.go": "fluent"},
+		errorCheck:     expect.ErrorNil,
+	})
+	run("EmptyConfiguredStyle", testCase{
+		files: map[string]string{
+			"mock.json":      `{"style": ""}`,
+			"store/store.go": strings.Replace(store, "%s", "minimal", 1),
+		},
+		config:         Config{Style: "fluent"},
+		expectedStyles: map[string]string{"store/shared_mock.go": "minimal"},
+		errorCheck:     expect.ErrorNil,
+	})
+	run("DifferentStyles", testCase{
+		files: map[string]string{
+			"store/store.go": strings.Replace(store, "%s", "fluent", 1),
+		},
+		errorCheck: expect.ErrorNonNil,
+	})
+}
+
+func TestRender(t *testing.T) {
+	file := iface.File{
+		Package:     "store",
+		PackagePath: "example.com/store",
+		SourceFiles: []string{"store.go"},
+		Interfaces: []iface.Interface{{
+			Name:     "Store",
+			MockName: "StoreMock",
+			Methods: iface.Methods{{
+				Name:    "Get",
+				Params:  iface.Params{{Name: "key", Type: "string"}},
+				Results: iface.Results{{Type: "int"}},
+			}},
+		}},
+	}
+	unknown := file
+	unknown.Style = "unknown"
+	standalone := file
+	standalone.Standalone = true
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	type testCase struct {
+		ctx            context.Context
+		config         Config
+		filesByPath    map[string]iface.File
+		expectedHeader string
+		expectedErrors []string
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			rendered, renderErr := testCase.config.Render(testCase.ctx, testCase.filesByPath)
+			if testCase.expectedErrors != nil {
+				expect.ErrorNonNil(t, renderErr)
+				expect.Equal(t, strings.Split(renderErr.Error(), "\n"), testCase.expectedErrors)
+				return
+			}
+			expect.ErrorNil(t, renderErr)
+			expect.Equal(t, len(rendered), len(testCase.filesByPath))
+			for outputPath := range testCase.filesByPath {
+				header, _, _ := strings.Cut(string(rendered[outputPath]), "\n")
+				expect.Equal(t, header, testCase.expectedHeader)
+			}
+		})
+	}
+
+	run("Success", testCase{
+		ctx: context.Background(),
+		filesByPath: map[string]iface.File{
+			"/store/a_mock.go": file,
+			"/store/b_mock.go": file,
+		},
+		expectedHeader: "// Code generated by mock from store.go. DO NOT EDIT.",
+	})
+	run("Version", testCase{
+		ctx:    context.Background(),
+		config: Config{Version: "v1.2.3", Jobs: 1},
+		filesByPath: map[string]iface.File{
+			"/store/store_mock.go": file,
+		},
+		expectedHeader: "// Code generated by mock v1.2.3 from store.go. DO NOT EDIT.",
+	})
+	run("Standalone", testCase{
+		ctx:    context.Background(),
+		config: Config{Version: "v1.2.3", Jobs: 1},
+		filesByPath: map[string]iface.File{
+			"/store/store_mock.go": standalone,
+		},
+		expectedHeader: "// Code generated by mock v1.2.3 for Store from store.go. DO NOT EDIT.",
+	})
+	run("Errors", testCase{
+		ctx: context.Background(),
+		filesByPath: map[string]iface.File{
+			"/store/b_mock.go": unknown,
+			"/store/c_mock.go": file,
+			"/store/a_mock.go": unknown,
+		},
+		expectedErrors: []string{
+			`loading template for /store/a_mock.go: unknown style "unknown" (expected one of minimal, recorder, fluent, moq, gomock)`,
+			`loading template for /store/b_mock.go: unknown style "unknown" (expected one of minimal, recorder, fluent, moq, gomock)`,
+		},
+	})
+	run("Cancelled", testCase{
+		ctx: cancelled,
+		filesByPath: map[string]iface.File{
+			"/store/store_mock.go": file,
+		},
+		expectedErrors: []string{"context canceled"},
+	})
+}
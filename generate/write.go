@@ -0,0 +1,78 @@
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// WriteFiles writes the given files, keyed by path, creating their directories
+// if necessary, then deletes the files at the paths to remove. Every file is
+// written to a temporary file before any is renamed into place, so if any
+// can't be written, none are changed. Otherwise, the errors replacing or
+// deleting individual files are joined.
+func WriteFiles(files map[string][]byte, remove []string) error {
+	var (
+		paths     = slices.Sorted(maps.Keys(files))
+		tempPaths = map[string]string{}
+		errs      []error
+	)
+	for _, path := range paths {
+		tempPath, stageErr := stageFile(path, files[path])
+		if stageErr != nil {
+			errs = append(errs, fmt.Errorf("writing %s: %w", path, stageErr))
+			continue
+		}
+		tempPaths[path] = tempPath
+	}
+	if len(errs) > 0 {
+		for _, tempPath := range tempPaths {
+			os.Remove(tempPath)
+		}
+		return errors.Join(errs...)
+	}
+
+	for _, path := range paths {
+		if renameErr := os.Rename(tempPaths[path], path); renameErr != nil {
+			os.Remove(tempPaths[path])
+			errs = append(errs, fmt.Errorf("writing %s: %w", path, renameErr))
+		}
+	}
+	for _, path := range remove {
+		if removeErr := os.Remove(path); removeErr != nil {
+			errs = append(errs, fmt.Errorf("pruning %s: %w", path, removeErr))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// stageFile writes the given contents to a temporary file alongside the given
+// path, creating its directory if necessary, and returns the temporary
+// file's path. The temporary file is hidden from the go command by its leading
+// dot, and has the existing file's permissions, or 0644 if there isn't one.
+func stageFile(path string, contents []byte) (string, error) {
+	dir := filepath.Dir(path)
+	if mkdirErr := os.MkdirAll(dir, 0o755); mkdirErr != nil {
+		return "", fmt.Errorf("creating output directory: %w", mkdirErr)
+	}
+	mode := fs.FileMode(0o644)
+	if info, statErr := os.Stat(path); statErr == nil {
+		mode = info.Mode().Perm()
+	}
+
+	temp, createErr := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if createErr != nil {
+		return "", createErr
+	}
+	_, writeErr := temp.Write(contents)
+	closeErr := temp.Close()
+	if stageErr := errors.Join(writeErr, closeErr, os.Chmod(temp.Name(), mode)); stageErr != nil {
+		os.Remove(temp.Name())
+		return "", stageErr
+	}
+	return temp.Name(), nil
+}
@@ -0,0 +1,110 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nicheinc/expect"
+)
+
+func TestWriteFiles(t *testing.T) {
+	type testCase struct {
+		// Existing files, relative to the test's directory
+		existing map[string]string
+		// Files to write and remove, relative to the test's directory
+		files      map[string]string
+		remove     []string
+		errorCheck expect.ErrorCheck
+		// The files afterwards, relative to the test's directory
+		expected map[string]string
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			dir := t.TempDir()
+			for path, contents := range testCase.existing {
+				if writeErr := os.WriteFile(filepath.Join(dir, path), []byte(contents), 0o644); writeErr != nil {
+					t.Fatal(writeErr)
+				}
+			}
+			var (
+				files  = map[string][]byte{}
+				remove []string
+			)
+			for path, contents := range testCase.files {
+				files[filepath.Join(dir, path)] = []byte(contents)
+			}
+			for _, path := range testCase.remove {
+				remove = append(remove, filepath.Join(dir, path))
+			}
+
+			err := WriteFiles(files, remove)
+			testCase.errorCheck(t, err)
+
+			actual := map[string]string{}
+			entries, readErr := os.ReadDir(dir)
+			if readErr != nil {
+				t.Fatal(readErr)
+			}
+			for _, entry := range entries {
+				if entry.IsDir() {
+					continue
+				}
+				contents, readErr := os.ReadFile(filepath.Join(dir, entry.Name()))
+				if readErr != nil {
+					t.Fatal(readErr)
+				}
+				actual[entry.Name()] = string(contents)
+			}
+			expect.Equal(t, actual, testCase.expected)
+		})
+	}
+
+	run("Success", testCase{
+		existing: map[string]string{
+			"a_mock.go": "old",
+			"c_mock.go": "orphaned",
+		},
+		files: map[string]string{
+			"a_mock.go": "new",
+			"b_mock.go": "created",
+		},
+		remove:     []string{"c_mock.go"},
+		errorCheck: expect.ErrorNil,
+		expected: map[string]string{
+			"a_mock.go": "new",
+			"b_mock.go": "created",
+		},
+	})
+	run("WriteError", testCase{
+		existing: map[string]string{
+			"a_mock.go": "old",
+			"file":      "not a directory",
+		},
+		files: map[string]string{
+			"a_mock.go":      "new",
+			"file/b_mock.go": "unwritable",
+		},
+		remove:     []string{"c_mock.go"},
+		errorCheck: expect.ErrorNonNil,
+		expected: map[string]string{
+			"a_mock.go": "old",
+			"file":      "not a directory",
+		},
+	})
+	run("PruneError", testCase{
+		existing: map[string]string{
+			"a_mock.go": "old",
+		},
+		files: map[string]string{
+			"a_mock.go": "new",
+		},
+		remove:     []string{"c_mock.go"},
+		errorCheck: expect.ErrorNonNil,
+		expected: map[string]string{
+			"a_mock.go": "new",
+		},
+	})
+}
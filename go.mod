@@ -3,13 +3,13 @@ module github.com/nicheinc/mock
 go 1.25.1
 
 require (
+	github.com/google/go-cmp v0.7.0
 	github.com/nicheinc/expect v0.2.0
+	golang.org/x/sync v0.16.0
 	golang.org/x/tools v0.36.0
 )
 
 require (
-	github.com/google/go-cmp v0.7.0 // indirect
 	golang.org/x/exp v0.0.0-20250819193227-8b4c13bb791b // indirect
 	golang.org/x/mod v0.27.0 // indirect
-	golang.org/x/sync v0.16.0 // indirect
 )
@@ -0,0 +1,282 @@
+package gomock
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Call is an expected call to a mock's method, which is configured by chaining
+// calls to its methods, e.g. mock.EXPECT().Get("key").Return(1, nil).Times(2).
+type Call struct {
+	t          TestHelper
+	receiver   any
+	method     string
+	methodType reflect.Type
+	args       []Matcher
+	// Where the call was expected, for messages
+	origin string
+
+	preReqs  []*Call
+	minCalls int
+	maxCalls int
+	numCalls int
+	// Functions run when the call is made, in order. The results of the last
+	// one returning non-nil results are the call's results.
+	actions []func([]any) []any
+}
+
+// newCall returns an expected call to the method of the given type, which is
+// expected once by default and returns zero values.
+func newCall(t TestHelper, receiver any, method string, methodType reflect.Type, args []any, origin string) *Call {
+	t.Helper()
+	matchers := make([]Matcher, len(args))
+	for i, arg := range args {
+		if matcher, isMatcher := arg.(Matcher); isMatcher {
+			matchers[i] = matcher
+		} else if arg == nil {
+			// A nil argument matches any nil value, including typed ones.
+			matchers[i] = Nil()
+		} else {
+			matchers[i] = Eq(arg)
+		}
+	}
+	zeros := func([]any) []any {
+		results := make([]any, methodType.NumOut())
+		for i := range results {
+			results[i] = reflect.Zero(methodType.Out(i)).Interface()
+		}
+		return results
+	}
+	return &Call{
+		t:          t,
+		receiver:   receiver,
+		method:     method,
+		methodType: methodType,
+		args:       matchers,
+		origin:     origin,
+		minCalls:   1,
+		maxCalls:   1,
+		actions:    []func([]any) []any{zeros},
+	}
+}
+
+// Return sets the results of the call, which must match the method's result
+// types.
+func (c *Call) Return(rets ...any) *Call {
+	c.t.Helper()
+	if len(rets) != c.methodType.NumOut() {
+		c.t.Fatalf("wrong number of arguments to Return for %T.%v: got %d, want %d [%s]", c.receiver, c.method, len(rets), c.methodType.NumOut(), c.origin)
+	}
+	for i, ret := range rets {
+		want := c.methodType.Out(i)
+		if ret == nil {
+			switch want.Kind() {
+			case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice, reflect.UnsafePointer:
+				continue
+			}
+			c.t.Fatalf("argument %d to Return for %T.%v is nil, but %v is not nillable [%s]", i, c.receiver, c.method, want, c.origin)
+		} else if got := reflect.TypeOf(ret); !got.AssignableTo(want) {
+			c.t.Fatalf("wrong type of argument %d to Return for %T.%v: %v is not assignable to %v [%s]", i, c.receiver, c.method, got, want, c.origin)
+		}
+	}
+	c.actions = append(c.actions, func([]any) []any { return rets })
+	return c
+}
+
+// Do sets a function to run when the call is made, with its arguments. The
+// function's results, if any, are ignored.
+func (c *Call) Do(f any) *Call {
+	c.t.Helper()
+	c.checkFunc("Do", f)
+	c.actions = append(c.actions, func(args []any) []any {
+		callFunc(f, args)
+		return nil
+	})
+	return c
+}
+
+// DoAndReturn sets a function to run when the call is made, with its
+// arguments, whose results are the call's results.
+func (c *Call) DoAndReturn(f any) *Call {
+	c.t.Helper()
+	c.checkFunc("DoAndReturn", f)
+	c.actions = append(c.actions, func(args []any) []any {
+		results := callFunc(f, args)
+		rets := make([]any, len(results))
+		for i, result := range results {
+			rets[i] = result.Interface()
+		}
+		return rets
+	})
+	return c
+}
+
+// checkFunc fails the test if f isn't a function.
+func (c *Call) checkFunc(name string, f any) {
+	c.t.Helper()
+	if f == nil || reflect.TypeOf(f).Kind() != reflect.Func {
+		c.t.Fatalf("argument to %s for %T.%v is not a function: %v [%s]", name, c.receiver, c.method, f, c.origin)
+	}
+}
+
+// callFunc calls the function with the given arguments, which are passed
+// individually even if it's variadic.
+func callFunc(f any, args []any) []reflect.Value {
+	v := reflect.ValueOf(f)
+	ft := v.Type()
+	vArgs := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		if arg != nil {
+			vArgs[i] = reflect.ValueOf(arg)
+			continue
+		}
+		if ft.IsVariadic() && i >= ft.NumIn()-1 {
+			vArgs[i] = reflect.Zero(ft.In(ft.NumIn() - 1).Elem())
+		} else {
+			vArgs[i] = reflect.Zero(ft.In(i))
+		}
+	}
+	return v.Call(vArgs)
+}
+
+// Times sets the number of times the call is expected, which is 1 by default.
+func (c *Call) Times(n int) *Call {
+	c.minCalls, c.maxCalls = n, n
+	return c
+}
+
+// MinTimes sets the minimum number of times the call is expected. Unless
+// MaxTimes was called, it removes the default upper limit.
+func (c *Call) MinTimes(n int) *Call {
+	c.minCalls = n
+	if c.maxCalls == 1 {
+		c.maxCalls = 1e8
+	}
+	return c
+}
+
+// MaxTimes sets the maximum number of times the call is expected. Unless
+// MinTimes was called, it removes the default lower limit.
+func (c *Call) MaxTimes(n int) *Call {
+	c.maxCalls = n
+	if c.minCalls == 1 {
+		c.minCalls = 0
+	}
+	return c
+}
+
+// AnyTimes allows the call to be made any number of times, including none.
+func (c *Call) AnyTimes() *Call {
+	c.minCalls, c.maxCalls = 0, 1e8
+	return c
+}
+
+// After requires the call to be made after the given call has been made
+// enough times.
+func (c *Call) After(preReq *Call) *Call {
+	c.t.Helper()
+	if c == preReq {
+		c.t.Fatalf("A call isn't allowed to be its own prerequisite")
+	}
+	c.preReqs = append(c.preReqs, preReq)
+	return c
+}
+
+// InOrder requires the given calls to be made in order.
+func InOrder(calls ...*Call) {
+	for i := 1; i < len(calls); i++ {
+		calls[i].After(calls[i-1])
+	}
+}
+
+// String describes the call and where it was expected.
+func (c *Call) String() string {
+	args := make([]string, len(c.args))
+	for i, arg := range c.args {
+		args[i] = arg.String()
+	}
+	return fmt.Sprintf("%T.%v(%s) %s", c.receiver, c.method, strings.Join(args, ", "), c.origin)
+}
+
+// matches returns an error describing the first argument that doesn't match,
+// if any. If the method is variadic, the matchers may match its variadic
+// arguments individually, or the last matcher may match them as a slice.
+func (c *Call) matches(args []any) error {
+	if !c.methodType.IsVariadic() || len(c.args) == len(args) {
+		if len(c.args) != len(args) {
+			return fmt.Errorf("%d: expected %d arguments, got %d", min(len(c.args), len(args)), len(c.args), len(args))
+		}
+		matchErr := matchArgs(c.args, args)
+		if matchErr == nil || !c.methodType.IsVariadic() {
+			return matchErr
+		}
+	}
+
+	// Match the variadic arguments as a slice.
+	fixed := c.methodType.NumIn() - 1
+	if len(c.args) != fixed+1 || len(args) < fixed {
+		return fmt.Errorf("%d: expected %d arguments, got %d", min(len(c.args), len(args)), len(c.args), len(args))
+	}
+	if matchErr := matchArgs(c.args[:fixed], args[:fixed]); matchErr != nil {
+		return matchErr
+	}
+	variadic := reflect.MakeSlice(c.methodType.In(fixed), 0, len(args)-fixed)
+	for _, arg := range args[fixed:] {
+		if arg == nil {
+			variadic = reflect.Append(variadic, reflect.Zero(variadic.Type().Elem()))
+		} else {
+			variadic = reflect.Append(variadic, reflect.ValueOf(arg))
+		}
+	}
+	if !c.args[fixed].Matches(variadic.Interface()) {
+		return fmt.Errorf("%d: expected %v, got %v", fixed, c.args[fixed], variadic.Interface())
+	}
+	return nil
+}
+
+// matchArgs returns an error describing the first argument that doesn't match
+// its matcher, if any.
+func matchArgs(matchers []Matcher, args []any) error {
+	for i, matcher := range matchers {
+		if !matcher.Matches(args[i]) {
+			return fmt.Errorf("%d: expected %v, got %v", i, matcher, args[i])
+		}
+	}
+	return nil
+}
+
+// exhausted reports whether the call has been made the maximum number of
+// times.
+func (c *Call) exhausted() bool {
+	return c.numCalls >= c.maxCalls
+}
+
+// satisfied reports whether the call has been made the minimum number of
+// times.
+func (c *Call) satisfied() bool {
+	return c.numCalls >= c.minCalls
+}
+
+// unsatisfiedPreReq returns the first of the call's prerequisites that hasn't
+// been made enough times, if any.
+func (c *Call) unsatisfiedPreReq() *Call {
+	for _, preReq := range c.preReqs {
+		if !preReq.satisfied() {
+			return preReq
+		}
+	}
+	return nil
+}
+
+// run runs the call's actions with the given arguments, returning the results
+// of the last one determining them.
+func (c *Call) run(args []any) []any {
+	var rets []any
+	for _, action := range c.actions {
+		if results := action(args); results != nil {
+			rets = results
+		}
+	}
+	return rets
+}
@@ -0,0 +1,188 @@
+// Package gomock is the runtime support for mocks generated in the gomock
+// style. It provides a subset of go.uber.org/mock/gomock's API, so that tests
+// written against mocks generated by mockgen keep working after switching
+// generators, without depending on that module.
+package gomock
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// TestReporter is the subset of *testing.T used to report failures.
+type TestReporter interface {
+	Errorf(format string, args ...any)
+	Fatalf(format string, args ...any)
+}
+
+// TestHelper is a TestReporter that can mark its callers as test helpers, such
+// as *testing.T.
+type TestHelper interface {
+	TestReporter
+	Helper()
+}
+
+// cleanuper is implemented by test reporters, such as *testing.T, that can
+// run functions when the test finishes.
+type cleanuper interface {
+	Cleanup(func())
+}
+
+// Controller tracks the expected calls to the mocks created with it, and
+// checks the actual calls against them.
+type Controller struct {
+	// T reports failures. It's exported for generated mocks, which mark
+	// themselves as test helpers.
+	T TestHelper
+
+	mu       sync.Mutex
+	expected []*Call
+	finished bool
+}
+
+// NewController returns a controller reporting failures to the given
+// reporter. If the reporter is a *testing.T, or otherwise supports cleanup
+// functions, missing calls are reported when the test finishes.
+func NewController(t TestReporter) *Controller {
+	helper, isHelper := t.(TestHelper)
+	if !isHelper {
+		helper = nopHelper{t}
+	}
+	ctrl := &Controller{T: helper}
+	if c, isCleanuper := t.(cleanuper); isCleanuper {
+		c.Cleanup(func() {
+			ctrl.T.Helper()
+			ctrl.finish(true)
+		})
+	}
+	return ctrl
+}
+
+// nopHelper is a TestHelper whose Helper method does nothing.
+type nopHelper struct {
+	TestReporter
+}
+
+func (nopHelper) Helper() {}
+
+// RecordCall records an expected call to the given method of the receiver,
+// with arguments matching the given matchers or values.
+func (ctrl *Controller) RecordCall(receiver any, method string, args ...any) *Call {
+	ctrl.T.Helper()
+	recv := reflect.ValueOf(receiver)
+	for i := 0; i < recv.Type().NumMethod(); i++ {
+		if recv.Type().Method(i).Name == method {
+			return ctrl.recordCall(receiver, method, recv.Method(i).Type(), args, callerInfo(2))
+		}
+	}
+	ctrl.T.Fatalf("gomock: failed finding method %s on %T", method, receiver)
+	panic("unreachable")
+}
+
+// RecordCallWithMethodType records an expected call to the given method of
+// the receiver, whose type is given, with arguments matching the given
+// matchers or values.
+func (ctrl *Controller) RecordCallWithMethodType(receiver any, method string, methodType reflect.Type, args ...any) *Call {
+	ctrl.T.Helper()
+	return ctrl.recordCall(receiver, method, methodType, args, callerInfo(2))
+}
+
+// recordCall records an expected call made at the given origin.
+func (ctrl *Controller) recordCall(receiver any, method string, methodType reflect.Type, args []any, origin string) *Call {
+	call := newCall(ctrl.T, receiver, method, methodType, args, origin)
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	ctrl.expected = append(ctrl.expected, call)
+	return call
+}
+
+// Call records a call to the given method of the receiver with the given
+// arguments, and returns the results of the first matching expected call,
+// failing the test if there's none. Variadic arguments are passed
+// individually.
+func (ctrl *Controller) Call(receiver any, method string, args ...any) []any {
+	ctrl.T.Helper()
+	call, matchErr := ctrl.match(receiver, method, args)
+	if matchErr != nil {
+		ctrl.T.Fatalf("Unexpected call to %T.%s(%v) at %s because: %s", receiver, method, args, callerInfo(3), matchErr)
+	}
+	// Actions run without the lock held, since they may call other mocks.
+	return call.run(args)
+}
+
+// match finds the first expected call matching the actual call that can still
+// be made, and counts the call against it.
+func (ctrl *Controller) match(receiver any, method string, args []any) (*Call, error) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	var reasons []string
+	for _, call := range ctrl.expected {
+		if call.receiver != receiver || call.method != method {
+			continue
+		}
+		if call.exhausted() {
+			reasons = append(reasons, fmt.Sprintf("expected call at %s has already been called the max number of times", call.origin))
+			continue
+		}
+		if matchErr := call.matches(args); matchErr != nil {
+			reasons = append(reasons, fmt.Sprintf("expected call at %s doesn't match the argument at index %s", call.origin, matchErr))
+			continue
+		}
+		if preReq := call.unsatisfiedPreReq(); preReq != nil {
+			reasons = append(reasons, fmt.Sprintf("expected call at %s must be made after %s", call.origin, preReq))
+			continue
+		}
+		call.numCalls++
+		return call, nil
+	}
+	if len(reasons) == 0 {
+		return nil, fmt.Errorf("there are no expected calls of the method %q for that receiver", method)
+	}
+	return nil, fmt.Errorf("%s", strings.Join(reasons, "\n"))
+}
+
+// Finish fails the test if any expected calls haven't been made enough times.
+// Controllers created with a *testing.T call it when the test finishes, so
+// calling it explicitly is only necessary otherwise.
+func (ctrl *Controller) Finish() {
+	ctrl.T.Helper()
+	ctrl.finish(false)
+}
+
+// finish reports the missing calls. Only the first call does anything, unless
+// it's the cleanup function, which tolerates an explicit call.
+func (ctrl *Controller) finish(cleanup bool) {
+	ctrl.T.Helper()
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	if ctrl.finished {
+		if !cleanup {
+			ctrl.T.Fatalf("Controller.Finish was called more than once")
+		}
+		return
+	}
+	ctrl.finished = true
+
+	var missing bool
+	for _, call := range ctrl.expected {
+		if !call.satisfied() {
+			ctrl.T.Errorf("missing call(s) to %v", call)
+			missing = true
+		}
+	}
+	if missing {
+		ctrl.T.Fatalf("aborting test due to missing call(s)")
+	}
+}
+
+// callerInfo returns the file and line of the caller the given number of
+// frames up the stack, where 0 identifies callerInfo's caller.
+func callerInfo(skip int) string {
+	if _, file, line, ok := runtime.Caller(skip + 1); ok {
+		return fmt.Sprintf("%s:%d", file, line)
+	}
+	return "unknown file"
+}
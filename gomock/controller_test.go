@@ -0,0 +1,217 @@
+package gomock
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nicheinc/expect"
+)
+
+// fatal is panicked by reporter.Fatalf, to stop the code under test like
+// testing.T.FailNow would.
+type fatal struct{}
+
+// reporter is a TestHelper recording the failures it's given.
+type reporter struct {
+	errors []string
+}
+
+func (r *reporter) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *reporter) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	panic(fatal{})
+}
+
+func (r *reporter) Helper() {}
+
+// run calls f, recovering from reporter.Fatalf.
+func (r *reporter) run(f func()) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			if _, isFatal := recovered.(fatal); !isFatal {
+				panic(recovered)
+			}
+		}
+	}()
+	f()
+}
+
+// store is a hand-written mock in the gomock style.
+type store struct {
+	ctrl *Controller
+}
+
+func (s *store) Get(key string) (int, error) {
+	ret := s.ctrl.Call(s, "Get", key)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (s *store) Put(key string, values ...int) {
+	args := []any{key}
+	for _, value := range values {
+		args = append(args, value)
+	}
+	s.ctrl.Call(s, "Put", args...)
+}
+
+func (s *store) expectGet(key any) *Call {
+	return s.ctrl.RecordCallWithMethodType(s, "Get", reflect.TypeOf((*store)(nil).Get), key)
+}
+
+func (s *store) expectPut(key any, values ...any) *Call {
+	return s.ctrl.RecordCallWithMethodType(s, "Put", reflect.TypeOf((*store)(nil).Put), append([]any{key}, values...)...)
+}
+
+func TestController(t *testing.T) {
+	type testCase struct {
+		// Records expectations, then makes calls
+		test           func(t *testing.T, s *store)
+		expectedErrors []string
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			r := &reporter{}
+			s := &store{ctrl: NewController(r)}
+			r.run(func() {
+				testCase.test(t, s)
+				s.ctrl.Finish()
+			})
+			expect.Equal(t, len(r.errors), len(testCase.expectedErrors))
+			for i, expected := range testCase.expectedErrors {
+				if i < len(r.errors) && !strings.Contains(r.errors[i], expected) {
+					t.Errorf("expected error %d to contain %q, got %q", i, expected, r.errors[i])
+				}
+			}
+		})
+	}
+
+	run("Return", testCase{
+		test: func(t *testing.T, s *store) {
+			s.expectGet("a").Return(1, nil)
+			value, getErr := s.Get("a")
+			expect.Equal(t, value, 1)
+			expect.ErrorNil(t, getErr)
+		},
+	})
+	run("ZeroValues", testCase{
+		test: func(t *testing.T, s *store) {
+			s.expectGet(Any())
+			value, getErr := s.Get("a")
+			expect.Equal(t, value, 0)
+			expect.ErrorNil(t, getErr)
+		},
+	})
+	run("FirstMatch", testCase{
+		test: func(t *testing.T, s *store) {
+			s.expectGet("a").Return(1, nil)
+			s.expectGet(Any()).Return(2, nil).Times(2)
+			var values []int
+			for _, key := range []string{"b", "a", "c"} {
+				value, _ := s.Get(key)
+				values = append(values, value)
+			}
+			expect.Equal(t, values, []int{2, 1, 2})
+		},
+	})
+	run("DoAndReturn", testCase{
+		test: func(t *testing.T, s *store) {
+			var keys []string
+			s.expectGet(Any()).Do(func(key string) {
+				keys = append(keys, key)
+			}).DoAndReturn(func(key string) (int, error) {
+				return len(key), nil
+			}).AnyTimes()
+			first, _ := s.Get("a")
+			second, _ := s.Get("abc")
+			expect.Equal(t, []int{first, second}, []int{1, 3})
+			expect.Equal(t, keys, []string{"a", "abc"})
+		},
+	})
+	run("Variadic/Individual", testCase{
+		test: func(t *testing.T, s *store) {
+			s.expectPut("a", 1, Any())
+			s.Put("a", 1, 2)
+		},
+	})
+	run("Variadic/Slice", testCase{
+		test: func(t *testing.T, s *store) {
+			s.expectPut("a", []int{1, 2})
+			s.expectPut("b", Len(0))
+			s.Put("a", 1, 2)
+			s.Put("b")
+		},
+	})
+	run("InOrder", testCase{
+		test: func(t *testing.T, s *store) {
+			InOrder(
+				s.expectGet("a"),
+				s.expectGet("b"),
+			)
+			s.Get("b")
+		},
+		expectedErrors: []string{"must be made after"},
+	})
+	run("Unexpected/Arguments", testCase{
+		test: func(t *testing.T, s *store) {
+			s.expectGet("a")
+			s.Get("b")
+		},
+		expectedErrors: []string{"Unexpected call to *gomock.store.Get([b])"},
+	})
+	run("Unexpected/Exhausted", testCase{
+		test: func(t *testing.T, s *store) {
+			s.expectGet("a")
+			s.Get("a")
+			s.Get("a")
+		},
+		expectedErrors: []string{"has already been called the max number of times"},
+	})
+	run("Unexpected/Method", testCase{
+		test: func(t *testing.T, s *store) {
+			s.Get("a")
+		},
+		expectedErrors: []string{`there are no expected calls of the method "Get"`},
+	})
+	run("Missing", testCase{
+		test: func(t *testing.T, s *store) {
+			s.expectGet("a").MinTimes(2)
+			s.expectPut("b")
+			s.Get("a")
+		},
+		expectedErrors: []string{
+			"missing call(s) to *gomock.store.Get(is equal to a (string))",
+			"missing call(s) to *gomock.store.Put(is equal to b (string))",
+			"aborting test due to missing call(s)",
+		},
+	})
+	run("Return/WrongCount", testCase{
+		test: func(t *testing.T, s *store) {
+			s.expectGet("a").Return(1)
+		},
+		expectedErrors: []string{"wrong number of arguments to Return for *gomock.store.Get: got 1, want 2"},
+	})
+	run("Return/WrongType", testCase{
+		test: func(t *testing.T, s *store) {
+			s.expectGet("a").Return("1", nil)
+		},
+		expectedErrors: []string{"wrong type of argument 0 to Return for *gomock.store.Get: string is not assignable to int"},
+	})
+}
+
+func TestNewControllerCleanup(t *testing.T) {
+	var s *store
+	t.Run("Subtest", func(t *testing.T) {
+		s = &store{ctrl: NewController(t)}
+		s.expectGet("a").AnyTimes()
+	})
+	expect.Equal(t, s.ctrl.finished, true)
+}
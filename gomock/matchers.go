@@ -0,0 +1,103 @@
+package gomock
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Matcher matches the arguments of expected calls. Arguments given to expected
+// calls that aren't matchers are matched with Eq, or Nil if they're nil.
+type Matcher interface {
+	// Matches reports whether x matches.
+	Matches(x any) bool
+	// String describes what the matcher matches.
+	String() string
+}
+
+// Any returns a matcher that matches anything.
+func Any() Matcher { return anyMatcher{} }
+
+type anyMatcher struct{}
+
+func (anyMatcher) Matches(any) bool { return true }
+func (anyMatcher) String() string   { return "is anything" }
+
+// Eq returns a matcher that matches values deeply equal to x, as determined by
+// reflect.DeepEqual. A value of a different type matches if x is assignable to
+// its type and equal after conversion.
+func Eq(x any) Matcher { return eqMatcher{x} }
+
+type eqMatcher struct {
+	x any
+}
+
+func (m eqMatcher) Matches(x any) bool {
+	if m.x == nil || x == nil {
+		return m.x == x
+	}
+	expected, actual := reflect.ValueOf(m.x), reflect.ValueOf(x)
+	if expected.Type() != actual.Type() && expected.Type().AssignableTo(actual.Type()) {
+		return reflect.DeepEqual(expected.Convert(actual.Type()).Interface(), x)
+	}
+	return reflect.DeepEqual(m.x, x)
+}
+
+func (m eqMatcher) String() string { return fmt.Sprintf("is equal to %v (%T)", m.x, m.x) }
+
+// Nil returns a matcher that matches nil, including nil values of nillable
+// types such as pointers and slices.
+func Nil() Matcher { return nilMatcher{} }
+
+type nilMatcher struct{}
+
+func (nilMatcher) Matches(x any) bool {
+	if x == nil {
+		return true
+	}
+	v := reflect.ValueOf(x)
+	switch v.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice, reflect.UnsafePointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+func (nilMatcher) String() string { return "is nil" }
+
+// Not returns a matcher that matches what the given matcher doesn't. If x
+// isn't a matcher, it's matched with Eq.
+func Not(x any) Matcher {
+	if matcher, isMatcher := x.(Matcher); isMatcher {
+		return notMatcher{matcher}
+	}
+	return notMatcher{Eq(x)}
+}
+
+type notMatcher struct {
+	m Matcher
+}
+
+func (m notMatcher) Matches(x any) bool { return !m.m.Matches(x) }
+func (m notMatcher) String() string     { return "not(" + m.m.String() + ")" }
+
+// Len returns a matcher that matches arrays, channels, maps, slices, and
+// strings of the given length.
+func Len(n int) Matcher { return lenMatcher{n} }
+
+type lenMatcher struct {
+	n int
+}
+
+func (m lenMatcher) Matches(x any) bool {
+	if x == nil {
+		return false
+	}
+	v := reflect.ValueOf(x)
+	switch v.Kind() {
+	case reflect.Array, reflect.Chan, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == m.n
+	}
+	return false
+}
+
+func (m lenMatcher) String() string { return fmt.Sprintf("has length %d", m.n) }
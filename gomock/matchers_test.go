@@ -0,0 +1,94 @@
+package gomock
+
+import (
+	"testing"
+
+	"github.com/nicheinc/expect"
+)
+
+func TestMatchers(t *testing.T) {
+	type name string
+	type testCase struct {
+		matcher  Matcher
+		x        any
+		expected bool
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			expect.Equal(t, testCase.matcher.Matches(testCase.x), testCase.expected)
+		})
+	}
+
+	run("Any", testCase{
+		matcher:  Any(),
+		x:        nil,
+		expected: true,
+	})
+	run("Eq/Equal", testCase{
+		matcher:  Eq([]int{1, 2}),
+		x:        []int{1, 2},
+		expected: true,
+	})
+	run("Eq/NotEqual", testCase{
+		matcher:  Eq([]int{1, 2}),
+		x:        []int{1},
+		expected: false,
+	})
+	run("Eq/DifferentType", testCase{
+		matcher:  Eq(1),
+		x:        int64(1),
+		expected: false,
+	})
+	run("Eq/NamedType", testCase{
+		matcher:  Eq(name("a")),
+		x:        "a",
+		expected: false,
+	})
+	run("Eq/Nil", testCase{
+		matcher:  Eq(nil),
+		x:        nil,
+		expected: true,
+	})
+	run("Nil/Untyped", testCase{
+		matcher:  Nil(),
+		x:        nil,
+		expected: true,
+	})
+	run("Nil/Typed", testCase{
+		matcher:  Nil(),
+		x:        (*int)(nil),
+		expected: true,
+	})
+	run("Nil/NonNil", testCase{
+		matcher:  Nil(),
+		x:        0,
+		expected: false,
+	})
+	run("Not/Value", testCase{
+		matcher:  Not(1),
+		x:        2,
+		expected: true,
+	})
+	run("Not/Matcher", testCase{
+		matcher:  Not(Nil()),
+		x:        nil,
+		expected: false,
+	})
+	run("Len/Slice", testCase{
+		matcher:  Len(2),
+		x:        []string{"a", "b"},
+		expected: true,
+	})
+	run("Len/String", testCase{
+		matcher:  Len(2),
+		x:        "abc",
+		expected: false,
+	})
+	run("Len/Int", testCase{
+		matcher:  Len(0),
+		x:        0,
+		expected: false,
+	})
+}
@@ -0,0 +1,186 @@
+package iface
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build/constraint"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// These lists should be kept in sync with the GOOS and GOARCH values recognized
+// by go/build.
+var (
+	knownOS = map[string]bool{
+		"aix": true, "android": true, "darwin": true, "dragonfly": true,
+		"freebsd": true, "hurd": true, "illumos": true, "ios": true, "js": true,
+		"linux": true, "nacl": true, "netbsd": true, "openbsd": true,
+		"plan9": true, "solaris": true, "wasip1": true, "windows": true,
+		"zos": true,
+	}
+	unixOS = map[string]bool{
+		"aix": true, "android": true, "darwin": true, "dragonfly": true,
+		"freebsd": true, "hurd": true, "illumos": true, "ios": true,
+		"linux": true, "netbsd": true, "openbsd": true, "solaris": true,
+	}
+	knownArch = map[string]bool{
+		"386": true, "amd64": true, "amd64p32": true, "arm": true, "armbe": true,
+		"arm64": true, "arm64be": true, "loong64": true, "mips": true,
+		"mipsle": true, "mips64": true, "mips64le": true, "mips64p32": true,
+		"mips64p32le": true, "ppc": true, "ppc64": true, "ppc64le": true,
+		"riscv": true, "riscv64": true, "s390": true, "s390x": true,
+		"sparc": true, "sparc64": true, "wasm": true,
+	}
+)
+
+// getBuildConstraint combines the build constraints of a mock file's source
+// files, whether from //go:build lines or implied by their filenames, along
+// with any extra constraints, into a single constraint. It returns nil if there
+// are no constraints, and an error if they can never be satisfied together.
+func getBuildConstraint(sourceFileNodes []*ast.File, filenames []string, extra []constraint.Expr) (constraint.Expr, error) {
+	var exprs []constraint.Expr
+	for _, fileNode := range sourceFileNodes {
+		expr, parseErr := fileBuildConstraint(fileNode)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		if expr != nil {
+			exprs = append(exprs, expr)
+		}
+	}
+	for _, filename := range filenames {
+		if expr := filenameBuildConstraint(filename); expr != nil {
+			exprs = append(exprs, expr)
+		}
+	}
+	exprs = append(exprs, extra...)
+
+	// Break the constraints into their conjuncts, then sort and de-duplicate
+	// them, so that the result is deterministic and free of redundancy in the
+	// common case where the source files share constraints.
+	var conjuncts []constraint.Expr
+	for _, expr := range exprs {
+		conjuncts = appendConjuncts(conjuncts, expr)
+	}
+	exprs = conjuncts
+	slices.SortFunc(exprs, func(a, b constraint.Expr) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	exprs = slices.CompactFunc(exprs, func(a, b constraint.Expr) bool {
+		return a.String() == b.String()
+	})
+	if len(exprs) == 0 {
+		return nil, nil
+	}
+	combined := exprs[0]
+	for _, expr := range exprs[1:] {
+		combined = &constraint.AndExpr{X: combined, Y: expr}
+	}
+	if !satisfiable(combined) {
+		return nil, fmt.Errorf("build constraints of the mocked interfaces conflict: %s", combined)
+	}
+	return combined, nil
+}
+
+// appendConjuncts appends the operands of a (possibly nested) && expression, or
+// else the expression itself, to the given slice.
+func appendConjuncts(conjuncts []constraint.Expr, expr constraint.Expr) []constraint.Expr {
+	if and, isAnd := expr.(*constraint.AndExpr); isAnd {
+		return appendConjuncts(appendConjuncts(conjuncts, and.X), and.Y)
+	}
+	return append(conjuncts, expr)
+}
+
+// fileBuildConstraint returns the constraint from the file's //go:build line,
+// or nil if it doesn't have one.
+func fileBuildConstraint(fileNode *ast.File) (constraint.Expr, error) {
+	for _, group := range fileNode.Comments {
+		// Build constraints must appear before the package clause.
+		if group.Pos() >= fileNode.Package {
+			break
+		}
+		for _, comment := range group.List {
+			if !constraint.IsGoBuild(comment.Text) {
+				continue
+			}
+			expr, parseErr := constraint.Parse(comment.Text)
+			if parseErr != nil {
+				return nil, fmt.Errorf("parsing build constraint %q: %v", comment.Text, parseErr)
+			}
+			return expr, nil
+		}
+	}
+	return nil, nil
+}
+
+// filenameBuildConstraint returns the constraint implied by a filename's GOOS
+// and/or GOARCH suffix (e.g. store_linux_amd64.go), or nil if there isn't one.
+// It follows the rules of go/build.
+func filenameBuildConstraint(filename string) constraint.Expr {
+	name := strings.TrimSuffix(filepath.Base(filename), ".go")
+	// Everything before the first underscore is ignored.
+	i := strings.Index(name, "_")
+	if i < 0 {
+		return nil
+	}
+	parts := strings.Split(name[i:], "_")
+	if n := len(parts); n > 0 && parts[n-1] == "test" {
+		parts = parts[:n-1]
+	}
+	n := len(parts)
+	switch {
+	case n >= 2 && knownOS[parts[n-2]] && knownArch[parts[n-1]]:
+		return &constraint.AndExpr{
+			X: &constraint.TagExpr{Tag: parts[n-2]},
+			Y: &constraint.TagExpr{Tag: parts[n-1]},
+		}
+	case n >= 1 && (knownOS[parts[n-1]] || knownArch[parts[n-1]]):
+		return &constraint.TagExpr{Tag: parts[n-1]}
+	}
+	return nil
+}
+
+// satisfiable reports whether there's some combination of GOOS, GOARCH, and
+// other tags for which the given constraint is satisfied.
+func satisfiable(expr constraint.Expr) bool {
+	// Collect the tags other than GOOS and GOARCH values, which are free to be
+	// set in any combination.
+	var freeTags []string
+	expr.Eval(func(tag string) bool {
+		if !knownOS[tag] && !knownArch[tag] && tag != "unix" && !slices.Contains(freeTags, tag) {
+			freeTags = append(freeTags, tag)
+		}
+		return false
+	})
+	// Bail out rather than enumerate an excessive number of combinations.
+	if len(freeTags) > 10 {
+		return true
+	}
+
+	for goos := range knownOS {
+		for goarch := range knownArch {
+			for set := range 1 << len(freeTags) {
+				ok := expr.Eval(func(tag string) bool {
+					switch {
+					case tag == goos || tag == goarch:
+						return true
+					case tag == "unix":
+						return unixOS[goos]
+					// These GOOS values imply others, per go/build.
+					case tag == "linux" && goos == "android",
+						tag == "solaris" && goos == "illumos",
+						tag == "darwin" && goos == "ios":
+						return true
+					}
+					i := slices.Index(freeTags, tag)
+					return i >= 0 && set&(1<<i) != 0
+				})
+				if ok {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package iface
+
+import (
+	"go/build/constraint"
+	"testing"
+
+	"github.com/nicheinc/expect"
+)
+
+func TestFilenameBuildConstraint(t *testing.T) {
+	type testCase struct {
+		filename string
+		expected string
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			var actual string
+			if expr := filenameBuildConstraint(testCase.filename); expr != nil {
+				actual = expr.String()
+			}
+			expect.Equal(t, actual, testCase.expected)
+		})
+	}
+
+	run("None", testCase{
+		filename: "/src/store.go",
+		expected: "",
+	})
+	run("OS", testCase{
+		filename: "/src/store_linux.go",
+		expected: "linux",
+	})
+	run("Arch", testCase{
+		filename: "/src/store_arm64.go",
+		expected: "arm64",
+	})
+	run("OSAndArch", testCase{
+		filename: "/src/store_windows_amd64.go",
+		expected: "windows && amd64",
+	})
+	run("Test", testCase{
+		filename: "/src/store_linux_test.go",
+		expected: "linux",
+	})
+	run("OSOnly", testCase{
+		filename: "/src/linux.go",
+		expected: "",
+	})
+	run("UnknownSuffix", testCase{
+		filename: "/src/store_mock.go",
+		expected: "",
+	})
+}
+
+func TestSatisfiable(t *testing.T) {
+	type testCase struct {
+		expr     string
+		expected bool
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			expr, err := constraint.Parse("//go:build " + testCase.expr)
+			expect.ErrorNil(t, err)
+			expect.Equal(t, satisfiable(expr), testCase.expected)
+		})
+	}
+
+	run("Tag", testCase{
+		expr:     "integration",
+		expected: true,
+	})
+	run("TagAndNotTag", testCase{
+		expr:     "integration && !integration",
+		expected: false,
+	})
+	run("OSAndArch", testCase{
+		expr:     "linux && amd64",
+		expected: true,
+	})
+	run("TwoOSes", testCase{
+		expr:     "linux && windows",
+		expected: false,
+	})
+	run("TwoArches", testCase{
+		expr:     "386 && amd64",
+		expected: false,
+	})
+	run("ImpliedOS", testCase{
+		expr:     "android && linux",
+		expected: true,
+	})
+	run("Unix", testCase{
+		expr:     "unix && windows",
+		expected: false,
+	})
+}
@@ -0,0 +1,95 @@
+package iface
+
+import (
+	"flag"
+	"fmt"
+	"go/build/constraint"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// directive represents the arguments of a go:mock directive.
+type directive struct {
+	// Output file for the interface, relative to its directory, if specified
+	outputFile string
+	// Options for the interface, defaulting to those passed to
+	// GetAllInterfaces
+	options Options
+}
+
+// parseDirective parses the arguments of a go:mock directive, which take the
+// form "[options] [output file]". The options are flags overriding the given
+// defaults for a single interface.
+func parseDirective(args string, defaults Options) (directive, error) {
+	fields, splitErr := splitArgs(args)
+	if splitErr != nil {
+		return directive{}, splitErr
+	}
+
+	d := directive{options: defaults}
+	flags := flag.NewFlagSet("go:mock", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.StringVar(&d.outputFile, "o", "", "")
+	flags.StringVar(&d.options.Tags, "tags", defaults.Tags, "")
+	flags.BoolVar(&d.options.TestOutput, "test", defaults.TestOutput, "")
+	flags.BoolVar(&d.options.Lenient, "lenient", defaults.Lenient, "")
+	flags.Var(&d.options.OnlyMethods, "only", "")
+	flags.Var(&d.options.ExcludeMethods, "exclude", "")
+	flags.StringVar(&d.options.MockName, "name", defaults.MockName, "")
+	flags.StringVar(&d.options.NamePattern, "name-pattern", defaults.NamePattern, "")
+	flags.StringVar(&d.options.Style, "style", defaults.Style, "")
+	flags.StringVar(&d.options.Template, "template", defaults.Template, "")
+	if parseErr := flags.Parse(fields); parseErr != nil {
+		return directive{}, parseErr
+	}
+
+	// The output file may also be given as a positional argument, which
+	// predates the -o option.
+	switch flags.NArg() {
+	case 0:
+	case 1:
+		if d.outputFile != "" {
+			return directive{}, fmt.Errorf("output file given both by -o (%s) and as an argument (%s)", d.outputFile, flags.Arg(0))
+		}
+		d.outputFile = flags.Arg(0)
+	default:
+		return directive{}, fmt.Errorf("unexpected arguments after output file: %s", strings.Join(flags.Args()[1:], " "))
+	}
+
+	if d.options.Tags != "" {
+		if _, parseErr := constraint.Parse("//go:build " + d.options.Tags); parseErr != nil {
+			return directive{}, fmt.Errorf("invalid -tags: %v", parseErr)
+		}
+	}
+	return d, nil
+}
+
+// splitArgs splits a go:mock directive's arguments on whitespace. As in
+// go:generate directives, quoted strings use Go syntax and may contain spaces.
+func splitArgs(args string) ([]string, error) {
+	var fields []string
+	for {
+		args = strings.TrimLeftFunc(args, unicode.IsSpace)
+		if args == "" {
+			return fields, nil
+		}
+		if args[0] == '"' || args[0] == '`' {
+			quoted, unquoteErr := strconv.QuotedPrefix(args)
+			if unquoteErr != nil {
+				return nil, fmt.Errorf("unterminated quoted string: %s", args)
+			}
+			field, _ := strconv.Unquote(quoted)
+			fields = append(fields, field)
+			args = args[len(quoted):]
+			continue
+		}
+		end := strings.IndexFunc(args, unicode.IsSpace)
+		if end < 0 {
+			end = len(args)
+		}
+		fields = append(fields, args[:end])
+		args = args[end:]
+	}
+}
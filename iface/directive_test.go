@@ -0,0 +1,102 @@
+package iface
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nicheinc/expect"
+)
+
+func TestParseDirective(t *testing.T) {
+	type testCase struct {
+		args       string
+		defaults   Options
+		expected   directive
+		errorCheck expect.ErrorCheck
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			actual, err := parseDirective(testCase.args, testCase.defaults)
+			testCase.errorCheck(t, err)
+			expect.Equal(t, actual, testCase.expected, cmp.AllowUnexported(directive{}))
+		})
+	}
+
+	run("Success/Empty", testCase{
+		args:     "",
+		defaults: Options{OutputFile: "default.go", Lenient: true},
+		expected: directive{
+			options: Options{OutputFile: "default.go", Lenient: true},
+		},
+		errorCheck: expect.ErrorNil,
+	})
+	run("Success/PositionalOutputFile", testCase{
+		args: " sink_mock.go",
+		expected: directive{
+			outputFile: "sink_mock.go",
+		},
+		errorCheck: expect.ErrorNil,
+	})
+	run("Success/Options", testCase{
+		args: `-o x_mock.go -lenient -test -tags "linux && !cgo"`,
+		expected: directive{
+			outputFile: "x_mock.go",
+			options: Options{
+				TestOutput: true,
+				Tags:       "linux && !cgo",
+				Lenient:    true,
+			},
+		},
+		errorCheck: expect.ErrorNil,
+	})
+	run("Success/OverrideDefault", testCase{
+		args:     "-lenient=false x_mock.go",
+		defaults: Options{Lenient: true},
+		expected: directive{
+			outputFile: "x_mock.go",
+			options:    Options{Lenient: false},
+		},
+		errorCheck: expect.ErrorNil,
+	})
+	run("Success/MethodLists", testCase{
+		args:     `-only Get,Set -exclude " Close , ,Open"`,
+		defaults: Options{OnlyMethods: MethodList{"Default"}},
+		expected: directive{
+			options: Options{
+				OnlyMethods:    MethodList{"Get", "Set"},
+				ExcludeMethods: MethodList{"Close", "Open"},
+			},
+		},
+		errorCheck: expect.ErrorNil,
+	})
+	run("Success/Template", testCase{
+		args:     "-template templates/mock.tmpl",
+		defaults: Options{Template: "/default.tmpl"},
+		expected: directive{
+			options: Options{Template: "templates/mock.tmpl"},
+		},
+		errorCheck: expect.ErrorNil,
+	})
+	run("Error/UnknownOption", testCase{
+		args:       "-unknown",
+		errorCheck: expect.ErrorNonNil,
+	})
+	run("Error/OutputFileTwice", testCase{
+		args:       "-o a_mock.go b_mock.go",
+		errorCheck: expect.ErrorNonNil,
+	})
+	run("Error/ExtraArguments", testCase{
+		args:       "a_mock.go -lenient",
+		errorCheck: expect.ErrorNonNil,
+	})
+	run("Error/InvalidTags", testCase{
+		args:       "-tags linux&&",
+		errorCheck: expect.ErrorNonNil,
+	})
+	run("Error/UnterminatedQuote", testCase{
+		args:       `-tags "linux`,
+		errorCheck: expect.ErrorNonNil,
+	})
+}
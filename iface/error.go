@@ -2,6 +2,7 @@ package iface
 
 import (
 	"fmt"
+	"go/token"
 	"strings"
 
 	"golang.org/x/tools/go/packages"
@@ -18,3 +19,21 @@ func (e *TypeErrors) Error() string {
 	}
 	return fmt.Sprintf("encountered type errors: \n%s", strings.Join(strs, "\n"))
 }
+
+// PositionError is an error attributed to a position in the source, usually
+// that of the go:mock directive responsible for it.
+type PositionError struct {
+	Pos token.Position
+	Err error
+}
+
+func (e *PositionError) Error() string {
+	if !e.Pos.IsValid() {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %v", e.Pos, e.Err)
+}
+
+func (e *PositionError) Unwrap() error {
+	return e.Err
+}
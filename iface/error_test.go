@@ -0,0 +1,32 @@
+package iface
+
+import (
+	"errors"
+	"go/token"
+	"testing"
+
+	"github.com/nicheinc/expect"
+)
+
+func TestPositionError(t *testing.T) {
+	type testCase struct {
+		pos      token.Position
+		expected string
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			err := &PositionError{Pos: testCase.pos, Err: errors.New("S is not an interface type")}
+			expect.Equal(t, err.Error(), testCase.expected)
+		})
+	}
+
+	run("Valid", testCase{
+		pos:      token.Position{Filename: "/p/store.go", Line: 12, Column: 1},
+		expected: "/p/store.go:12:1: S is not an interface type",
+	})
+	run("Invalid", testCase{
+		expected: "S is not an interface type",
+	})
+}
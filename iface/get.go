@@ -2,9 +2,14 @@ package iface
 
 import (
 	"cmp"
+	"errors"
 	"fmt"
 	"go/ast"
+	"go/build/constraint"
+	"go/token"
 	"go/types"
+	"maps"
+	"path"
 	"path/filepath"
 	"slices"
 	"sort"
@@ -12,25 +17,187 @@ import (
 	"strings"
 	"unicode"
 
+	"golang.org/x/sync/errgroup"
 	"golang.org/x/tools/go/packages"
 )
 
 // fileInfo represents syntactic and type information for a file containing
 // interfaces to be mocked.
 type fileInfo struct {
-	pkg             *packages.Package
+	pkg       *packages.Package
+	outputPkg outputPackage
+	// Path of the output file, if known
+	outputPath      string
 	sourceFileNodes map[*ast.File]struct{}
-	objects         []types.Object
+	objects         []objectInfo
+	// Extra build constraints for the output file
+	tags []constraint.Expr
+	// Style and template for the output file, and the import paths they
+	// require
+	style           string
+	template        string
+	templateImports []string
+}
+
+// objectInfo represents a type declaration to be mocked, along with the options
+// from its go:mock directive.
+type objectInfo struct {
+	object  types.Object
+	options Options
+	// Position of the go:mock directive, or of the declaration if there's
+	// none, to which errors mocking the object are attributed
+	pos token.Position
+}
+
+// errorAt attributes the given error to the object's position.
+func (o objectInfo) errorAt(err error) error {
+	return &PositionError{Pos: o.pos, Err: err}
+}
+
+// external reports whether the mock file belongs to a package other than the
+// one declaring its interfaces.
+func (f fileInfo) external() bool {
+	return f.outputPkg.path != f.pkg.Types.Path()
+}
+
+// Options configures the output of GetAllInterfaces.
+type Options struct {
+	// OutputFile, if nonempty, is the output file for interfaces whose go:mock
+	// directives don't specify one.
+	OutputFile string
+	// OutputDir, if nonempty, is a directory mirroring the module's package
+	// tree, into which mocks are written instead of alongside their
+	// interfaces. A relative OutputDir is relative to the module root.
+	OutputDir string
+	// TestOutput determines whether interfaces' default output files are
+	// _mock_test.go files rather than _mock.go files, so that mocks are only
+	// compiled into tests.
+	TestOutput bool
+	// Tags, if nonempty, is a build constraint expression that output files
+	// must satisfy in addition to their source files' constraints.
+	Tags string
+	// Lenient determines whether mocks' methods return zero values, rather
+	// than failing the test and panicking, when their stubs are nil.
+	Lenient bool
+	// OnlyMethods, if nonempty, restricts mocks' stubs to the given methods.
+	// Mocks embed their interfaces to provide the remaining methods.
+	OnlyMethods MethodList
+	// ExcludeMethods omits the given methods from mocks' stubs. Mocks embed
+	// their interfaces to provide the omitted methods.
+	ExcludeMethods MethodList
+	// MockName, if nonempty, is the name of the mock type.
+	MockName string
+	// NamePattern, if nonempty, determines the name of the mock type when
+	// MockName is empty, by replacing its single "%s" with the interface name.
+	// The default is "%sMock".
+	NamePattern string
+	// Style is the name of the built-in template for mock files, e.g.
+	// "minimal". An empty style is the default one.
+	Style string
+	// Template, if nonempty, is the path of a custom template for mock files,
+	// which is layered over the style's template. A relative path in a
+	// go:mock directive is relative to the directive's file.
+	Template string
+	// TemplateImports, if non-nil, returns the import paths required by the
+	// given style and template, whose package names take precedence over
+	// those of the interfaces' imports.
+	TemplateImports func(style, template string) ([]string, error)
+}
+
+// templateImports returns the import paths required by the options' style and
+// template.
+func (o Options) templateImports() ([]string, error) {
+	if o.TemplateImports == nil {
+		return nil, nil
+	}
+	imports, templateErr := o.TemplateImports(o.Style, o.Template)
+	if templateErr != nil {
+		if o.Template == "" {
+			return nil, fmt.Errorf("loading %s: %w", styleName(o.Style), templateErr)
+		}
+		return nil, fmt.Errorf("loading %s: %w", templateName(o.Template), templateErr)
+	}
+	return imports, nil
+}
+
+// styleName describes the style with the given name in messages.
+func styleName(style string) string {
+	if style == "" {
+		return "the default style"
+	}
+	return fmt.Sprintf("the %s style", style)
+}
+
+// templateName describes the template with the given path in messages.
+func templateName(template string) string {
+	if template == "" {
+		return "the default template"
+	}
+	return "template " + template
+}
+
+// mockName returns the name of the mock type for the named interface.
+func (o Options) mockName(ifaceName string) (string, error) {
+	name := o.MockName
+	if name == "" {
+		pattern := cmp.Or(o.NamePattern, "%sMock")
+		if strings.Count(pattern, "%s") != 1 {
+			return "", fmt.Errorf(`name pattern %q must contain "%%s" exactly once`, pattern)
+		}
+		name = strings.Replace(pattern, "%s", ifaceName, 1)
+	}
+	if !token.IsIdentifier(name) {
+		return "", fmt.Errorf("mock name %q is not a valid identifier", name)
+	}
+	return name, nil
+}
+
+// MethodList is a list of method names, which implements flag.Value as a
+// comma-separated list.
+type MethodList []string
+
+func (l *MethodList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *MethodList) Set(value string) error {
+	*l = nil
+	for name := range strings.SplitSeq(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			*l = append(*l, name)
+		}
+	}
+	return nil
 }
 
 // GetAllInterfaces searches the given packages for interfaces annotated with a
 // "go:mock" directive, returning text-template-friendly representations grouped
-// by output file. If nonempty, defaultOutputFile will be used as the output
-// file for interfaces without an explicit output file.
-func GetAllInterfaces(pkgs []*packages.Package, defaultOutputFile string) (map[string]File, error) {
-	fileInfoByPath := map[string]*fileInfo{}
+// by output file. The options function returns the options for interfaces
+// declared in a given package, which their go:mock directives may override.
+// Up to concurrency files are constructed at once. Errors don't stop the
+// search, so that they can all be reported together: if there are any, they're
+// joined in the order of the directives and output paths, and no files are
+// returned. Each is a *PositionError attributed to the go:mock directive
+// responsible for it.
+func GetAllInterfaces(pkgs []*packages.Package, options func(*packages.Package) Options, concurrency int) (map[string]File, error) {
+	var (
+		fileInfoByPath = map[string]*fileInfo{}
+		errs           []error
+	)
 	for _, pkg := range pkgs {
+		// Test binaries' synthesized main packages contain no user code.
+		if isTestMain(pkg) {
+			continue
+		}
+		pkgOptions := options(pkg)
 		for _, fileNode := range pkg.Syntax {
+			// The test variant of a package (e.g. "p [p.test]") repeats the
+			// package's non-test files, which are covered by the package
+			// itself.
+			inputPath := pkg.Fset.File(fileNode.Pos()).Name()
+			if isTestVariant(pkg) && !isTestFile(inputPath) {
+				continue
+			}
 			ast.Inspect(fileNode, func(node ast.Node) bool {
 				// A nil node indicates we've finished traversing the AST.
 				if node == nil {
@@ -48,20 +215,26 @@ func GetAllInterfaces(pkgs []*packages.Package, defaultOutputFile string) (map[s
 						continue
 					}
 
+					pos := pkg.Fset.Position(comment.Pos())
+					errorAt := func(err error) {
+						errs = append(errs, &PositionError{Pos: pos, Err: err})
+					}
+
+					directive, directiveErr := parseDirective(args, pkgOptions)
+					if directiveErr != nil {
+						errorAt(fmt.Errorf("invalid go:mock directive: %v", directiveErr))
+						return false
+					}
+					if template := directive.options.Template; template != "" && !filepath.IsAbs(template) {
+						directive.options.Template = filepath.Join(filepath.Dir(inputPath), template)
+					}
+
 					// Build a qualified output pathname based on the output
 					// filename (or a default) and the input filepath.
-					var (
-						inputPath  = pkg.Fset.File(fileNode.Pos()).Name()
-						outputFile = strings.TrimSpace(args)
-						outputPath string
-					)
-					switch {
-					case outputFile != "":
-						outputPath = filepath.Join(filepath.Dir(inputPath), outputFile)
-					case defaultOutputFile != "":
-						outputPath = filepath.Join(filepath.Dir(inputPath), defaultOutputFile)
-					default:
-						outputPath = strings.TrimSuffix(inputPath, ".go") + "_mock.go"
+					outputPath, outputErr := getOutputPath(pkg, inputPath, directive.outputFile, directive.options)
+					if outputErr != nil {
+						errorAt(outputErr)
+						return false
 					}
 
 					for _, spec := range decl.Specs {
@@ -78,14 +251,55 @@ func GetAllInterfaces(pkgs []*packages.Package, defaultOutputFile string) (map[s
 						// Create the file info if it doesn't already exist, and
 						// add the newly discovered interface.
 						if _, fileInfoExists := fileInfoByPath[outputPath]; !fileInfoExists {
+							outputPkg, outputPkgErr := getOutputPackage(pkgs, pkg, outputPath)
+							if outputPkgErr != nil {
+								errorAt(outputPkgErr)
+								return false
+							}
+							templateImports, templateErr := directive.options.templateImports()
+							if templateErr != nil {
+								errorAt(templateErr)
+								return false
+							}
 							fileInfoByPath[outputPath] = &fileInfo{
 								pkg:             pkg,
+								outputPkg:       outputPkg,
+								outputPath:      outputPath,
 								sourceFileNodes: map[*ast.File]struct{}{},
+								style:           directive.options.Style,
+								template:        directive.options.Template,
+								templateImports: templateImports,
 							}
 						}
 						fileInfo := fileInfoByPath[outputPath]
+						// A file can only belong to one package, so mocks of
+						// interfaces from, say, package p and its external
+						// test package p_test can't share an output file.
+						if fileInfo.pkg != pkg {
+							errorAt(fmt.Errorf("output file %s would contain mocks for interfaces from both %s and %s", outputPath, fileInfo.pkg.ID, pkg.ID))
+							return false
+						}
+						// Likewise, a file is rendered with a single style and
+						// template.
+						if fileInfo.style != directive.options.Style {
+							errorAt(fmt.Errorf("output file %s would be rendered in both %s and %s", outputPath, styleName(fileInfo.style), styleName(directive.options.Style)))
+							return false
+						}
+						if fileInfo.template != directive.options.Template {
+							errorAt(fmt.Errorf("output file %s would be rendered with both %s and %s", outputPath, templateName(fileInfo.template), templateName(directive.options.Template)))
+							return false
+						}
 						fileInfo.sourceFileNodes[fileNode] = struct{}{}
-						fileInfo.objects = append(fileInfo.objects, object)
+						fileInfo.objects = append(fileInfo.objects, objectInfo{
+							object:  object,
+							options: directive.options,
+							pos:     pos,
+						})
+						if directive.options.Tags != "" {
+							// The tags were validated by parseDirective.
+							tags, _ := constraint.Parse("//go:build " + directive.options.Tags)
+							fileInfo.tags = append(fileInfo.tags, tags)
+						}
 						return true
 					}
 				}
@@ -94,22 +308,71 @@ func GetAllInterfaces(pkgs []*packages.Package, defaultOutputFile string) (map[s
 		}
 	}
 
+	var (
+		outputPaths = slices.Sorted(maps.Keys(fileInfoByPath))
+		files       = make([]File, len(outputPaths))
+		fileErrs    = make([]error, len(outputPaths))
+		group       errgroup.Group
+	)
+	group.SetLimit(max(concurrency, 1))
+	for i, outputPath := range outputPaths {
+		group.Go(func() error {
+			files[i], fileErrs[i] = getFile(*fileInfoByPath[outputPath])
+			return nil
+		})
+	}
+	group.Wait()
+	cycleErrs := checkImportCycles(outputPaths, fileInfoByPath, files, fileErrs)
+
+	if joinedErr := errors.Join(slices.Concat(errs, fileErrs, cycleErrs)...); joinedErr != nil {
+		return nil, joinedErr
+	}
 	filesByPath := map[string]File{}
-	for outputPath, fileInfo := range fileInfoByPath {
-		file, fileErr := getFile(*fileInfo)
-		if fileErr != nil {
-			return nil, fileErr
-		}
-		filesByPath[outputPath] = file
+	for i, outputPath := range outputPaths {
+		filesByPath[outputPath] = files[i]
 	}
 	return filesByPath, nil
 }
 
-// GetInterface searches the given package for the given interface and returns
-// its text-template-friendly representation.
-func GetInterface(pkg *packages.Package, ifaceName string) (File, error) {
-	// Find the interface by name
-	object := pkg.Types.Scope().Lookup(ifaceName)
+// GetInterface searches the given packages, which must comprise a single
+// package along with its test variants, for the given interface and returns its
+// text-template-friendly representation. Output file options are ignored.
+func GetInterface(pkgs []*packages.Package, ifaceName string, options Options) (File, error) {
+	// Set aside test mains, and make sure there's only one package, ignoring
+	// its test variants and external test package.
+	var candidates []*packages.Package
+	for _, pkg := range pkgs {
+		if isTestMain(pkg) {
+			continue
+		}
+		if len(candidates) > 0 {
+			if first, other := strings.TrimSuffix(candidates[0].PkgPath, "_test"), strings.TrimSuffix(pkg.PkgPath, "_test"); first != other {
+				return File{}, fmt.Errorf("found more than one package: %s and %s", first, other)
+			}
+		}
+		candidates = append(candidates, pkg)
+	}
+	if len(candidates) < 1 {
+		return File{}, fmt.Errorf("interface %s not found: no packages", ifaceName)
+	}
+
+	// Prefer the package itself, whose scope contains its non-test
+	// declarations, followed by its test variant and then its external test
+	// package.
+	slices.SortStableFunc(candidates, func(a, b *packages.Package) int {
+		return cmp.Or(
+			compareBool(isTestVariant(a), isTestVariant(b)),
+			compareBool(strings.HasSuffix(a.PkgPath, "_test"), strings.HasSuffix(b.PkgPath, "_test")),
+		)
+	})
+	pkg := candidates[0]
+	var object types.Object
+	for _, candidate := range candidates {
+		if object = candidate.Types.Scope().Lookup(ifaceName); object != nil {
+			pkg = candidate
+			break
+		}
+	}
 	if object == nil {
 		return File{}, fmt.Errorf("interface %s not found in package %s", ifaceName, pkg.Name)
 	}
@@ -126,21 +389,131 @@ func GetInterface(pkg *packages.Package, ifaceName string) (File, error) {
 		return File{}, fmt.Errorf("declaration for interface %s not found in package %s's syntax trees", ifaceName, pkg.Name)
 	}
 
-	return getFile(fileInfo{
+	templateImports, templateErr := options.templateImports()
+	if templateErr != nil {
+		return File{}, templateErr
+	}
+	fileInfo := fileInfo{
 		pkg:             pkg,
+		outputPkg:       outputPackage{name: pkg.Name, path: pkg.Types.Path()},
 		sourceFileNodes: map[*ast.File]struct{}{ifaceFileNode: {}},
-		objects:         []types.Object{object},
-	})
+		objects:         []objectInfo{{object: object, options: options, pos: pkg.Fset.Position(object.Pos())}},
+		style:           options.Style,
+		template:        options.Template,
+		templateImports: templateImports,
+	}
+	if options.Tags != "" {
+		tags, parseErr := constraint.Parse("//go:build " + options.Tags)
+		if parseErr != nil {
+			return File{}, fmt.Errorf("invalid tags: %v", parseErr)
+		}
+		fileInfo.tags = append(fileInfo.tags, tags)
+	}
+	file, getErr := getFile(fileInfo)
+	if getErr != nil {
+		return File{}, getErr
+	}
+	file.Standalone = true
+	return file, nil
+}
+
+// isTestMain reports whether the given package is the synthesized main package
+// of a test binary (e.g. "p.test").
+func isTestMain(pkg *packages.Package) bool {
+	return strings.HasSuffix(pkg.ID, ".test")
+}
+
+// isTestVariant reports whether the given package was compiled for a test
+// binary, i.e. it's either the test variant of a package (e.g. "p [p.test]") or
+// an external test package (e.g. "p_test [p.test]").
+func isTestVariant(pkg *packages.Package) bool {
+	return pkg.ID != pkg.PkgPath
 }
 
-// These packages should be kept in sync with references in template.tmpl.
-var defaultImports = map[string]bool{
-	"sync/atomic": true,
-	"testing":     true,
+// isTestFile reports whether the given filename is a Go test file.
+func isTestFile(filename string) bool {
+	return strings.HasSuffix(filename, "_test.go")
+}
+
+// compareBool orders false before true.
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
+
+// checkImportCycles returns an error for each of the given mock files that
+// belongs to a package other than its interfaces' and would import a package
+// that imports its own, directly or indirectly. Files that couldn't be
+// constructed, per the given errors, are skipped. The import graph is loaded
+// once per module.
+func checkImportCycles(outputPaths []string, fileInfoByPath map[string]*fileInfo, files []File, fileErrs []error) []error {
+	indexesByDir := map[string][]int{}
+	for i, outputPath := range outputPaths {
+		fileInfo := fileInfoByPath[outputPath]
+		if fileErrs[i] != nil || !fileInfo.external() || len(fileInfo.pkg.GoFiles) == 0 {
+			continue
+		}
+		dir := filepath.Dir(fileInfo.pkg.GoFiles[0])
+		if fileInfo.pkg.Module != nil {
+			dir = fileInfo.pkg.Module.Dir
+		}
+		indexesByDir[dir] = append(indexesByDir[dir], i)
+	}
+
+	var errs []error
+	for _, dir := range slices.Sorted(maps.Keys(indexesByDir)) {
+		// A mock file imports the packages it uses along with those its
+		// template requires.
+		var (
+			indexes       = indexesByDir[dir]
+			importsByFile = make([][]string, len(indexes))
+			allImports    = map[string]bool{}
+		)
+		for j, i := range indexes {
+			importPaths := slices.Clone(fileInfoByPath[outputPaths[i]].templateImports)
+			for _, imp := range files[i].Imports {
+				importPaths = append(importPaths, imp.Path)
+			}
+			slices.Sort(importPaths)
+			importsByFile[j] = slices.Compact(importPaths)
+			for _, importPath := range importsByFile[j] {
+				allImports[importPath] = true
+			}
+		}
+
+		graph, graphErr := importGraph(dir, slices.Sorted(maps.Keys(allImports)))
+		for j, i := range indexes {
+			fileInfo := fileInfoByPath[outputPaths[i]]
+			if graphErr != nil {
+				errs = append(errs, fileInfo.objects[0].errorAt(graphErr))
+				continue
+			}
+			for _, importPath := range importsByFile[j] {
+				if !importsPackage(graph, importPath, fileInfo.outputPkg.path) {
+					continue
+				}
+				message := fmt.Sprintf("mocking interfaces from package %s in package %s would create an import cycle", fileInfo.pkg.PkgPath, fileInfo.outputPkg.path)
+				if importPath != fileInfo.pkg.PkgPath {
+					message += fmt.Sprintf(" through package %s", importPath)
+				}
+				errs = append(errs, fileInfo.objects[0].errorAt(errors.New(message)))
+				break
+			}
+		}
+	}
+	return errs
 }
 
 // getFile uses syntactic and type information about a file of mockable
 // interfaces to construct a text-template-friendly representation of that file.
+// Errors concerning an interface are attributed to its directive, and those
+// concerning the file as a whole to its first interface's directive.
 func getFile(fileInfo fileInfo) (File, error) {
 	// Aggregate the source files' imports, along with their names (if renamed).
 	var imports []Import
@@ -159,9 +532,18 @@ func getFile(fileInfo fileInfo) (File, error) {
 		}
 	}
 
-	// Every mock file includes imports required to implement the mock itself.
-	for path := range defaultImports {
-		imports = append(imports, Import{Path: path})
+	// Every mock file includes imports required by its template to implement
+	// the mock itself, whose package names are assumed to match their paths.
+	for _, importPath := range fileInfo.templateImports {
+		imports = append(imports, Import{Path: importPath, Package: path.Base(importPath)})
+	}
+
+	// Mocks outside the interfaces' package must import it.
+	if fileInfo.external() {
+		imports = append(imports, Import{
+			Path:    fileInfo.pkg.Types.Path(),
+			Package: fileInfo.pkg.Name,
+		})
 	}
 
 	// If there are any conflicting imports (i.e. imports of different packages
@@ -169,9 +551,10 @@ func getFile(fileInfo fileInfo) (File, error) {
 	// rename them to resolve the conflicts. To ensure the packages we choose to
 	// rename are deterministic, we sort the packages.
 	slices.SortFunc(imports, func(a, b Import) int {
-		// Prioritize the default imports since the mock template assumes those
+		// Prioritize the template's imports since the template assumes those
 		// packages are not aliased.
-		aDflt, bDflt := defaultImports[a.Path], defaultImports[b.Path]
+		aDflt := slices.Contains(fileInfo.templateImports, a.Path)
+		bDflt := slices.Contains(fileInfo.templateImports, b.Path)
 		switch {
 		case aDflt && !bDflt:
 			return -1
@@ -185,9 +568,17 @@ func getFile(fileInfo fileInfo) (File, error) {
 		)
 	})
 
-	// Remove consecutive equal elements, in case the same import is included in
-	// two source files.
-	imports = slices.Compact(imports)
+	// Remove duplicate imports, in case the same import is included in two
+	// source files or in a source file and the template.
+	seen := map[Import]bool{}
+	imports = slices.DeleteFunc(imports, func(imp Import) bool {
+		key := Import{Path: imp.Path, Name: imp.Name}
+		if seen[key] {
+			return true
+		}
+		seen[key] = true
+		return false
+	})
 
 	// Finally, ensure uniqueness of local package names.
 	packageTaken := map[string]bool{}
@@ -201,23 +592,72 @@ func getFile(fileInfo fileInfo) (File, error) {
 			}
 			name, incrementErr := incrementName(localName)
 			if incrementErr != nil {
-				return File{}, fmt.Errorf("resolving import conflict: %v", incrementErr)
+				return File{}, fileInfo.objects[0].errorAt(fmt.Errorf("resolving import conflict: %v", incrementErr))
 			}
 			imp.Name = name
 		}
 	}
 
 	var (
-		file      = File{Package: fileInfo.pkg.Name}
-		qualifier = qualify(fileInfo.pkg.Types, imports, &file.Imports)
+		file      = File{Package: fileInfo.outputPkg.name, PackagePath: fileInfo.outputPkg.path, Style: fileInfo.style, Template: fileInfo.template}
+		qualifier = qualify(fileInfo.outputPkg.path, imports, &file.Imports)
 	)
-	for _, object := range fileInfo.objects {
-		iface, ifaceErr := getInterface(fileInfo, qualifier, object)
+
+	// The mock file is subject to the build constraints of all its source
+	// files, along with any extra constraints.
+	var (
+		sourceFileNodes []*ast.File
+		sourceFilenames []string
+	)
+	for sourceFile := range fileInfo.sourceFileNodes {
+		sourceFileNodes = append(sourceFileNodes, sourceFile)
+		sourceFilenames = append(sourceFilenames, fileInfo.pkg.Fset.File(sourceFile.Pos()).Name())
+	}
+	buildConstraint, constraintErr := getBuildConstraint(sourceFileNodes, sourceFilenames, fileInfo.tags)
+	if constraintErr != nil {
+		return File{}, fileInfo.objects[0].errorAt(constraintErr)
+	}
+	if buildConstraint != nil {
+		file.BuildConstraint = buildConstraint.String()
+	}
+	file.GoVersion = fileGoVersion(fileInfo.pkg, buildConstraint)
+
+	// The header names the source files relative to the mock file, or by their
+	// base names if the mock file's location is unknown.
+	for _, sourceFilename := range sourceFilenames {
+		sourceFile := filepath.Base(sourceFilename)
+		if fileInfo.outputPath != "" {
+			if rel, relErr := filepath.Rel(filepath.Dir(fileInfo.outputPath), sourceFilename); relErr == nil {
+				sourceFile = filepath.ToSlash(rel)
+			}
+		}
+		file.SourceFiles = append(file.SourceFiles, sourceFile)
+	}
+	slices.Sort(file.SourceFiles)
+
+	// Report every interface that can't be mocked, not just the first.
+	var ifaceErrs []error
+	for _, objectInfo := range fileInfo.objects {
+		iface, ifaceErr := getInterface(fileInfo, qualifier, objectInfo, file.GoVersion)
 		if ifaceErr != nil {
-			return File{}, ifaceErr
+			ifaceErrs = append(ifaceErrs, objectInfo.errorAt(ifaceErr))
+			continue
 		}
 		file.Interfaces = append(file.Interfaces, iface)
 	}
+	if joinedErr := errors.Join(ifaceErrs...); joinedErr != nil {
+		return File{}, joinedErr
+	}
+
+	// Mocks outside the interfaces' package are subject to the usual
+	// restrictions on importing internal packages.
+	if fileInfo.external() {
+		for _, imp := range file.Imports {
+			if !canImport(fileInfo.outputPkg.path, imp.Path) {
+				return File{}, fileInfo.objects[0].errorAt(fmt.Errorf("mock package %s can't import internal package %s", fileInfo.outputPkg.path, imp.Path))
+			}
+		}
+	}
 	return file, nil
 }
 
@@ -246,8 +686,11 @@ func incrementName(name string) (string, error) {
 }
 
 // getInterface uses syntactic and type information about an interface to
-// construct a text-template-friendly representation of that interface.
-func getInterface(fileInfo fileInfo, qualifier types.Qualifier, object types.Object) (Interface, error) {
+// construct a text-template-friendly representation of that interface, whose
+// mock must be valid in the given Go language version.
+func getInterface(fileInfo fileInfo, qualifier types.Qualifier, objectInfo objectInfo, goVersion string) (Interface, error) {
+	object := objectInfo.object
+
 	// Validate that the object is indeed an interface declaration.
 	if _, isTypeName := object.(*types.TypeName); !isTypeName {
 		return Interface{}, fmt.Errorf("%s is not a named/defined type", object.Name())
@@ -257,18 +700,49 @@ func getInterface(fileInfo fileInfo, qualifier types.Qualifier, object types.Obj
 		return Interface{}, fmt.Errorf("%s is not an interface type", object.Name())
 	}
 
+	// Generic mocks require language features that the mock file's Go version
+	// may predate, in which case the interface's own declaration likely has
+	// type errors too.
+	if versionErr := checkGoVersion(object, goVersion); versionErr != nil {
+		return Interface{}, versionErr
+	}
+
 	// Make sure that none of the types involved in the interface's definition
 	// were invalid/had errors.
 	if !validateType(object.Type(), map[types.Type]bool{}) {
 		return Interface{}, &TypeErrors{Errs: fileInfo.pkg.Errors}
 	}
 
+	// Mocks outside the interface's package can only reference its exported
+	// identifiers.
+	if fileInfo.external() && !object.Exported() {
+		return Interface{}, fmt.Errorf("%s is unexported, so it can't be mocked outside package %s", object.Name(), fileInfo.pkg.Name)
+	}
+
 	// Begin assembling information about the interface.
-	iface := Interface{Name: object.Name()}
+	mockName, nameErr := objectInfo.options.mockName(object.Name())
+	if nameErr != nil {
+		return Interface{}, fmt.Errorf("naming mock of %s: %v", object.Name(), nameErr)
+	}
+	iface := Interface{
+		Name:        object.Name(),
+		MockName:    mockName,
+		PackagePath: fileInfo.pkg.PkgPath,
+		Position:    fileInfo.pkg.Fset.Position(object.Pos()),
+		Lenient:     objectInfo.options.Lenient,
+	}
+	if fileInfo.external() {
+		iface.Package = qualifier(fileInfo.pkg.Types)
+	}
 
 	// Record type parameter list info.
 	if typeParams := getTypeParams(object.Type()); typeParams != nil {
 		for typeParam := range typeParams.TypeParams() {
+			if fileInfo.external() {
+				if name := unexportedName(typeParam.Constraint(), fileInfo.outputPkg.path, map[types.Type]bool{}); name != "" {
+					return Interface{}, fmt.Errorf("type parameter %s of %s references %s, which isn't accessible from package %s", typeParam.Obj().Name(), object.Name(), name, fileInfo.outputPkg.name)
+				}
+			}
 			iface.TypeParams = append(iface.TypeParams, TypeParam{
 				Name:       typeParam.Obj().Name(),
 				Constraint: types.TypeString(typeParam.Constraint(), qualifier),
@@ -290,6 +764,17 @@ func getInterface(fileInfo fileInfo, qualifier types.Qualifier, object types.Obj
 				return Interface{}, fmt.Errorf("%s is not a method signature", methodObj.Name())
 			}
 
+			// Types outside the interface's package can't implement its
+			// unexported methods or reference its unexported identifiers.
+			if fileInfo.external() {
+				if !methodObj.Exported() {
+					return Interface{}, fmt.Errorf("method %s of %s is unexported, so it can't be mocked outside package %s", methodObj.Name(), object.Name(), fileInfo.pkg.Name)
+				}
+				if name := unexportedName(sig, fileInfo.outputPkg.path, map[types.Type]bool{}); name != "" {
+					return Interface{}, fmt.Errorf("method %s of %s references %s, which isn't accessible from package %s", methodObj.Name(), object.Name(), name, fileInfo.outputPkg.name)
+				}
+			}
+
 			// Keep track of the names and types of the parameters.
 			for paramObj := range sig.Params().Variables() {
 				param := Param{
@@ -322,9 +807,40 @@ func getInterface(fileInfo fileInfo, qualifier types.Qualifier, object types.Obj
 	// Preserve the original ordering of the methods.
 	sort.Sort(iface.Methods)
 
+	// Filter the methods, if requested. The mock embeds the interface to
+	// provide the methods that were filtered out.
+	filtered, filterErr := filterMethods(iface.Methods, objectInfo.options.OnlyMethods, objectInfo.options.ExcludeMethods)
+	if filterErr != nil {
+		return Interface{}, fmt.Errorf("filtering methods of %s: %v", object.Name(), filterErr)
+	}
+	iface.Partial = len(filtered) < len(iface.Methods)
+	iface.Methods = filtered
+
 	return iface, nil
 }
 
+// filterMethods returns the given methods, restricted to those in only (if
+// nonempty) and then excluding those in exclude. It returns an error if either
+// list names a method that doesn't exist.
+func filterMethods(methods Methods, only, exclude MethodList) (Methods, error) {
+	for _, name := range slices.Concat(only, exclude) {
+		if !slices.ContainsFunc(methods, func(method Method) bool { return method.Name == name }) {
+			return nil, fmt.Errorf("no method named %s", name)
+		}
+	}
+	var filtered Methods
+	for _, method := range methods {
+		if len(only) > 0 && !slices.Contains(only, method.Name) {
+			continue
+		}
+		if slices.Contains(exclude, method.Name) {
+			continue
+		}
+		filtered = append(filtered, method)
+	}
+	return filtered, nil
+}
+
 // getTypeParams returns type parameter list info for named types and aliases.
 // It returns nil for all other types.
 func getTypeParams(typ types.Type) *types.TypeParamList {
@@ -368,10 +884,10 @@ func explodeInterface(iface *types.Interface) []*types.Interface {
 	return result
 }
 
-func qualify(pkg *types.Package, imps []Import, usedImps *[]Import) types.Qualifier {
+func qualify(pkgPath string, imps []Import, usedImps *[]Import) types.Qualifier {
 	return func(other *types.Package) string {
 		// If the type is from this package, don't qualify it
-		if pkg == other {
+		if pkgPath == other.Path() {
 			return ""
 		}
 
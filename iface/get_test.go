@@ -1,9 +1,14 @@
 package iface
 
 import (
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/nicheinc/expect"
+	"github.com/nicheinc/mock/internal/testmodule"
+	"golang.org/x/tools/go/packages"
 )
 
 func TestIncrementName(t *testing.T) {
@@ -58,3 +63,256 @@ func TestIncrementName(t *testing.T) {
 		errorCheck: expect.ErrorNil,
 	})
 }
+
+func TestFilterMethods(t *testing.T) {
+	methods := Methods{{Name: "Get"}, {Name: "Set"}, {Name: "Close"}}
+	type testCase struct {
+		only       MethodList
+		exclude    MethodList
+		expected   Methods
+		errorCheck expect.ErrorCheck
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			actual, err := filterMethods(methods, testCase.only, testCase.exclude)
+			testCase.errorCheck(t, err)
+			expect.Equal(t, actual, testCase.expected, cmp.AllowUnexported(Method{}))
+		})
+	}
+
+	run("Success/NoFilters", testCase{
+		expected:   methods,
+		errorCheck: expect.ErrorNil,
+	})
+	run("Success/Only", testCase{
+		only:       MethodList{"Close", "Get"},
+		expected:   Methods{{Name: "Get"}, {Name: "Close"}},
+		errorCheck: expect.ErrorNil,
+	})
+	run("Success/Exclude", testCase{
+		exclude:    MethodList{"Set"},
+		expected:   Methods{{Name: "Get"}, {Name: "Close"}},
+		errorCheck: expect.ErrorNil,
+	})
+	run("Success/OnlyAndExclude", testCase{
+		only:       MethodList{"Get", "Set"},
+		exclude:    MethodList{"Set"},
+		expected:   Methods{{Name: "Get"}},
+		errorCheck: expect.ErrorNil,
+	})
+	run("Error/UnknownMethod", testCase{
+		only:       MethodList{"Delete"},
+		expected:   nil,
+		errorCheck: expect.ErrorNonNil,
+	})
+}
+
+func TestMockName(t *testing.T) {
+	type testCase struct {
+		options    Options
+		expected   string
+		errorCheck expect.ErrorCheck
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			actual, err := testCase.options.mockName("Store")
+			testCase.errorCheck(t, err)
+			expect.Equal(t, actual, testCase.expected)
+		})
+	}
+
+	run("Success/Default", testCase{
+		options:    Options{},
+		expected:   "StoreMock",
+		errorCheck: expect.ErrorNil,
+	})
+	run("Success/Pattern", testCase{
+		options:    Options{NamePattern: "Fake%s"},
+		expected:   "FakeStore",
+		errorCheck: expect.ErrorNil,
+	})
+	run("Success/Name", testCase{
+		options:    Options{MockName: "StubStore", NamePattern: "Fake%s"},
+		expected:   "StubStore",
+		errorCheck: expect.ErrorNil,
+	})
+	run("Error/PatternWithoutPlaceholder", testCase{
+		options:    Options{NamePattern: "Fake"},
+		expected:   "",
+		errorCheck: expect.ErrorNonNil,
+	})
+	run("Error/PatternWithTwoPlaceholders", testCase{
+		options:    Options{NamePattern: "%sFake%s"},
+		expected:   "",
+		errorCheck: expect.ErrorNonNil,
+	})
+	run("Error/InvalidIdentifier", testCase{
+		options:    Options{NamePattern: "%s-Mock"},
+		expected:   "",
+		errorCheck: expect.ErrorNonNil,
+	})
+}
+
+func TestGetAllInterfaces(t *testing.T) {
+	// mockFile summarizes a mock file found in a package.
+	type mockFile struct {
+		Package     string
+		PackagePath string
+		MockNames   []string
+	}
+	type testCase struct {
+		files         map[string]string
+		patterns      []string
+		options       Options
+		expected      map[string]mockFile
+		expectedError string
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			dir := testmodule.Write(t, testCase.files)
+			patterns := testCase.patterns
+			if len(patterns) == 0 {
+				patterns = []string{"./..."}
+			}
+			pkgs, loadErr := packages.Load(&packages.Config{
+				Dir:   dir,
+				Mode:  packages.LoadSyntax | packages.NeedModule | packages.NeedDeps,
+				Tests: true,
+			}, patterns...)
+			expect.ErrorNil(t, loadErr)
+			// The mock command loads packages without their dependencies, so
+			// their imports are stubs.
+			for _, pkg := range pkgs {
+				for importPath, imported := range pkg.Imports {
+					pkg.Imports[importPath] = &packages.Package{ID: imported.ID}
+				}
+			}
+
+			filesByPath, err := GetAllInterfaces(pkgs, func(*packages.Package) Options { return testCase.options }, 1)
+			if testCase.expectedError != "" {
+				expect.ErrorNonNil(t, err)
+				expect.Equal(t, strings.ReplaceAll(err.Error(), dir+string(filepath.Separator), ""), testCase.expectedError)
+				return
+			}
+			expect.ErrorNil(t, err)
+			actual := map[string]mockFile{}
+			for outputPath, file := range filesByPath {
+				rel, relErr := filepath.Rel(dir, outputPath)
+				expect.ErrorNil(t, relErr)
+				actualFile := mockFile{Package: file.Package, PackagePath: file.PackagePath}
+				for _, iface := range file.Interfaces {
+					actualFile.MockNames = append(actualFile.MockNames, iface.MockName)
+				}
+				actual[filepath.ToSlash(rel)] = actualFile
+			}
+			expect.Equal(t, actual, testCase.expected)
+		})
+	}
+
+	// Packages are loaded along with their test variants, which repeat their
+	// non-test files, but each interface is only mocked once.
+	run("NonTestFile", testCase{
+		files: map[string]string{
+			"p/p.go":      "package p\n\n//go:mock\ntype Getter interface{ Get() int }\n",
+			"p/p_test.go": "package p\n",
+		},
+		expected: map[string]mockFile{
+			"p/p_mock.go": {Package: "p", PackagePath: "example.com/m/p", MockNames: []string{"GetterMock"}},
+		},
+	})
+	run("NonTestFile/TestOutput", testCase{
+		files: map[string]string{
+			"p/p.go":      "package p\n\n//go:mock\ntype Getter interface{ Get() int }\n",
+			"p/p_test.go": "package p\n",
+		},
+		options: Options{TestOutput: true},
+		expected: map[string]mockFile{
+			"p/p_mock_test.go": {Package: "p", PackagePath: "example.com/m/p", MockNames: []string{"GetterMock"}},
+		},
+	})
+	// Mocks of interfaces declared in test files belong to the same package's
+	// test files.
+	run("InPackageTestFile", testCase{
+		files: map[string]string{
+			"p/p.go":      "package p\n",
+			"p/p_test.go": "package p\n\n//go:mock\ntype helper interface{ Help() }\n",
+		},
+		expected: map[string]mockFile{
+			"p/p_mock_test.go": {Package: "p", PackagePath: "example.com/m/p", MockNames: []string{"helperMock"}},
+		},
+	})
+	run("ExternalTestFile", testCase{
+		files: map[string]string{
+			"p/p.go":        "package p\n",
+			"p/ext_test.go": "package p_test\n\n//go:mock\ntype External interface{ Ext() }\n",
+		},
+		expected: map[string]mockFile{
+			"p/ext_mock_test.go": {Package: "p_test", PackagePath: "example.com/m/p_test", MockNames: []string{"ExternalMock"}},
+		},
+	})
+	run("AllFiles", testCase{
+		files: map[string]string{
+			"p/p.go":        "package p\n\n//go:mock\ntype Getter interface{ Get() int }\n",
+			"p/p_test.go":   "package p\n\n//go:mock\ntype helper interface{ Help() }\n",
+			"p/ext_test.go": "package p_test\n\n//go:mock\ntype External interface{ Ext() }\n",
+		},
+		expected: map[string]mockFile{
+			"p/p_mock.go":        {Package: "p", PackagePath: "example.com/m/p", MockNames: []string{"GetterMock"}},
+			"p/p_mock_test.go":   {Package: "p", PackagePath: "example.com/m/p", MockNames: []string{"helperMock"}},
+			"p/ext_mock_test.go": {Package: "p_test", PackagePath: "example.com/m/p_test", MockNames: []string{"ExternalMock"}},
+		},
+	})
+
+	// Package p imports x, which imports y, which imports mocks.
+	cycleFiles := map[string]string{
+		"p/p.go":           "package p\n\nimport _ \"example.com/m/x\"\n\n//go:mock\ntype Getter interface{ Get() int }\n",
+		"x/x.go":           "package x\n\nimport _ \"example.com/m/y\"\n",
+		"y/y.go":           "package y\n\nimport _ \"example.com/m/mocks\"\n",
+		"mocks/mocks.go":   "package mocks\n",
+		"other/other.go":   "package other\n",
+		"helper/helper.go": "package helper\n\nimport _ \"example.com/m/other\"\n",
+	}
+	run("ImportCycle/None", testCase{
+		files:   cycleFiles,
+		options: Options{OutputDir: "other"},
+		// The output directory mirrors the module's package tree.
+		expected: map[string]mockFile{
+			"other/p/p_mock.go": {Package: "p", PackagePath: "example.com/m/other/p", MockNames: []string{"GetterMock"}},
+		},
+	})
+	run("ImportCycle/Direct", testCase{
+		files:         cycleFiles,
+		options:       Options{OutputFile: "../x/p_mock.go"},
+		expectedError: "p/p.go:5:1: mocking interfaces from package example.com/m/p in package example.com/m/x would create an import cycle",
+	})
+	run("ImportCycle/Transitive", testCase{
+		files:         cycleFiles,
+		options:       Options{OutputFile: "../mocks/p_mock.go"},
+		expectedError: "p/p.go:5:1: mocking interfaces from package example.com/m/p in package example.com/m/mocks would create an import cycle",
+	})
+	// Packages that weren't loaded, whose imports are unknown to the caller,
+	// are followed too.
+	run("ImportCycle/UnloadedDependency", testCase{
+		files:         cycleFiles,
+		patterns:      []string{"./p"},
+		options:       Options{OutputFile: "../mocks/p_mock.go"},
+		expectedError: "p/p.go:5:1: mocking interfaces from package example.com/m/p in package example.com/m/mocks would create an import cycle",
+	})
+	// Every import of the mock file is followed, including the template's.
+	run("ImportCycle/TemplateImport", testCase{
+		files: cycleFiles,
+		options: Options{
+			OutputFile: "../other/p_mock.go",
+			TemplateImports: func(string, string) ([]string, error) {
+				return []string{"example.com/m/helper"}, nil
+			},
+		},
+		expectedError: "p/p.go:5:1: mocking interfaces from package example.com/m/p in package example.com/m/other would create an import cycle through package example.com/m/helper",
+	})
+}
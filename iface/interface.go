@@ -9,20 +9,63 @@ import (
 )
 
 type File struct {
-	Package    string
-	Imports    []Import
-	Interfaces []Interface
+	Package     string      `json:"package"`
+	PackagePath string      `json:"packagePath"`
+	Imports     []Import    `json:"imports"`
+	Interfaces  []Interface `json:"interfaces"`
+
+	// Expression for the file's //go:build line, if it has one
+	BuildConstraint string `json:"buildConstraint,omitempty"`
+	// Files declaring the mocked interfaces, relative to the mock file
+	SourceFiles []string `json:"sourceFiles"`
+	// Name of the built-in template for the file, or empty for the default
+	Style string `json:"style,omitempty"`
+	// Path of the custom template for the file, if it has one
+	Template string `json:"template,omitempty"`
+	// Go language version of the file, e.g. go1.21, if known, which limits the
+	// language features its mocks may use
+	GoVersion string `json:"goVersion,omitempty"`
+	// Whether the file mocks a single interface named on the command line,
+	// rather than the interfaces with go:mock directives
+	Standalone bool `json:"standalone,omitempty"`
+	// Version of mock generating the file, if known, which is set when it's
+	// rendered
+	Version string `json:"-"`
 }
 
 type Interface struct {
-	Name       string
-	TypeParams TypeParams
-	Methods    Methods
+	Name       string     `json:"name"`
+	MockName   string     `json:"mockName"`
+	TypeParams TypeParams `json:"typeParams,omitempty"`
+	Methods    Methods    `json:"methods"`
+
+	// Local name of the package declaring the interface, if it's not the
+	// mock's package
+	Package string `json:"package,omitempty"`
+	// Import path of the package declaring the interface
+	PackagePath string `json:"packagePath"`
+	// Position of the interface's declaration, which isn't serialized since
+	// it's only meaningful alongside the source files
+	Position token.Position `json:"-"`
+	// Whether the mock's methods return zero values when their stubs are nil
+	Lenient bool `json:"lenient,omitempty"`
+	// Whether Methods omits some of the interface's methods, in which case the
+	// mock embeds the interface to provide them
+	Partial bool `json:"partial,omitempty"`
+}
+
+// QualifiedName returns the interface's name, qualified by its package if it's
+// declared outside the mock's package.
+func (i Interface) QualifiedName() string {
+	if i.Package == "" {
+		return i.Name
+	}
+	return fmt.Sprintf("%s.%s", i.Package, i.Name)
 }
 
 type TypeParam struct {
-	Name       string
-	Constraint string
+	Name       string `json:"name"`
+	Constraint string `json:"constraint"`
 }
 
 func (t TypeParam) String() string {
@@ -56,9 +99,9 @@ func (t TypeParams) Names() string {
 }
 
 type Import struct {
-	Name    string
-	Path    string
-	Package string
+	Name    string `json:"name,omitempty"`
+	Path    string `json:"path"`
+	Package string `json:"package,omitempty"`
 }
 
 // LocalName returns the name that can be used to reference the imported package
@@ -75,9 +118,9 @@ func (i *Import) String() string {
 }
 
 type Method struct {
-	Name    string
-	Params  Params
-	Results Results
+	Name    string  `json:"name"`
+	Params  Params  `json:"params"`
+	Results Results `json:"results"`
 
 	// String representation of the interface explicitly requiring this method
 	srcIface string
@@ -109,9 +152,9 @@ func (m Methods) Less(i, j int) bool {
 }
 
 type Param struct {
-	Name     string
-	Type     string
-	Variadic bool
+	Name     string `json:"name,omitempty"`
+	Type     string `json:"type"`
+	Variadic bool   `json:"variadic,omitempty"`
 }
 
 func (p *Param) String() string {
@@ -138,27 +181,32 @@ func (ps Params) String() string {
 	return strings.Join(strs, ", ")
 }
 
-func (ps Params) NamedString() string {
-	var strs []string
+// Names returns the names of the parameters, naming unnamed and blank ones
+// after their positions, e.g. param1.
+func (ps Params) Names() []string {
+	var names []string
 	for i, p := range ps {
 		name := p.Name
 		if name == "" || name == "_" {
 			name = fmt.Sprintf("param%d", i+1)
 		}
+		names = append(names, name)
+	}
+	return names
+}
 
-		strs = append(strs, fmt.Sprintf("%s %s", name, p.TypeString()))
+func (ps Params) NamedString() string {
+	var strs []string
+	for i, name := range ps.Names() {
+		strs = append(strs, fmt.Sprintf("%s %s", name, ps[i].TypeString()))
 	}
 	return strings.Join(strs, ", ")
 }
 
 func (ps Params) ArgsString() string {
 	var args []string
-	for i, param := range ps {
-		arg := param.Name
-		if arg == "" || arg == "_" {
-			arg = fmt.Sprintf("param%d", i+1)
-		}
-		if param.Variadic {
+	for i, arg := range ps.Names() {
+		if ps[i].Variadic {
 			arg = fmt.Sprintf("%s...", arg)
 		}
 		args = append(args, arg)
@@ -167,8 +215,8 @@ func (ps Params) ArgsString() string {
 }
 
 type Result struct {
-	Name string
-	Type string
+	Name string `json:"name,omitempty"`
+	Type string `json:"type"`
 }
 
 func (r *Result) String() string {
@@ -180,6 +228,16 @@ func (r *Result) String() string {
 
 type Results []Result
 
+// ZeroString returns a comma-separated list of zero values for the results'
+// types.
+func (rs Results) ZeroString() string {
+	var zeros []string
+	for _, r := range rs {
+		zeros = append(zeros, fmt.Sprintf("*new(%s)", r.Type))
+	}
+	return strings.Join(zeros, ", ")
+}
+
 func (rs Results) String() string {
 	var (
 		strs  []string
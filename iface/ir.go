@@ -0,0 +1,43 @@
+package iface
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// IRVersion is the version of the intermediate representation's schema. It
+// must be incremented whenever the schema changes incompatibly. Adding optional
+// fields is compatible, since ReadIR ignores fields it doesn't know.
+const IRVersion = 1
+
+// IR is the intermediate representation of a set of mock files, which decouples
+// finding and analyzing the interfaces to mock from rendering their mocks.
+type IR struct {
+	// Version of the schema
+	Version int `json:"version"`
+	// Mock files, keyed by output path
+	Files map[string]File `json:"files"`
+}
+
+// WriteIR writes the intermediate representation of the given mock files, keyed
+// by output path, as JSON.
+func WriteIR(w io.Writer, filesByPath map[string]File) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(IR{Version: IRVersion, Files: filesByPath})
+}
+
+// ReadIR reads an intermediate representation written by WriteIR, returning its
+// mock files keyed by output path. Unknown fields, e.g. those added by a newer
+// version of mock within the same schema version, are ignored.
+func ReadIR(r io.Reader) (map[string]File, error) {
+	var ir IR
+	if decodeErr := json.NewDecoder(r).Decode(&ir); decodeErr != nil {
+		return nil, decodeErr
+	}
+	if ir.Version != IRVersion {
+		return nil, fmt.Errorf("unsupported IR version %d (expected %d)", ir.Version, IRVersion)
+	}
+	return ir.Files, nil
+}
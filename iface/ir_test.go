@@ -0,0 +1,84 @@
+package iface
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/nicheinc/expect"
+)
+
+func TestIR(t *testing.T) {
+	filesByPath := map[string]File{
+		"store/store_mock.go": {
+			Package:     "store",
+			PackagePath: "example.com/store",
+			Imports:     []Import{{Path: "context", Package: "context"}},
+			Interfaces: []Interface{{
+				Name:       "Store",
+				MockName:   "StoreMock",
+				TypeParams: TypeParams{{Name: "T", Constraint: "any"}},
+				Methods: Methods{{
+					Name:    "Get",
+					Params:  Params{{Name: "ctx", Type: "context.Context"}, {Name: "keys", Type: "[]T", Variadic: true}},
+					Results: Results{{Type: "T"}, {Name: "err", Type: "error"}},
+				}},
+				PackagePath: "example.com/store",
+				Lenient:     true,
+			}},
+			BuildConstraint: "linux",
+			SourceFiles:     []string{"store.go"},
+			Style:           "recorder",
+			GoVersion:       "go1.21",
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	expect.ErrorNil(t, WriteIR(buf, filesByPath))
+	actual, err := ReadIR(buf)
+	expect.ErrorNil(t, err)
+	expect.Equal(t, actual, filesByPath)
+
+}
+
+func TestReadIR(t *testing.T) {
+	type testCase struct {
+		input         string
+		expected      map[string]File
+		errorExpected bool
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			actual, err := ReadIR(strings.NewReader(testCase.input))
+			if testCase.errorExpected {
+				expect.ErrorNonNil(t, err)
+				return
+			}
+			expect.ErrorNil(t, err)
+			expect.Equal(t, actual, testCase.expected)
+		})
+	}
+
+	run("UnsupportedVersion", testCase{
+		input:         `{"version": 0, "files": {}}`,
+		errorExpected: true,
+	})
+	run("Malformed", testCase{
+		input:         `{"version": 1, "files": [}`,
+		errorExpected: true,
+	})
+	// Fields added by newer versions of mock are ignored.
+	run("UnknownFields", testCase{
+		input: `{"version": 1, "extra": true, "files": {"store_mock.go": {"package": "store", "packagePath": "example.com/store", "sourceFiles": ["store.go"], "extra": 1, "interfaces": [{"name": "Store", "mockName": "StoreMock", "packagePath": "example.com/store", "extra": "x"}]}}}`,
+		expected: map[string]File{
+			"store_mock.go": {
+				Package:     "store",
+				PackagePath: "example.com/store",
+				SourceFiles: []string{"store.go"},
+				Interfaces:  []Interface{{Name: "Store", MockName: "StoreMock", PackagePath: "example.com/store"}},
+			},
+		},
+	})
+}
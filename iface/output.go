@@ -0,0 +1,465 @@
+package iface
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"go/build"
+	"go/token"
+	"go/types"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// outputPackage identifies the package a mock file belongs to.
+type outputPackage struct {
+	name string
+	path string
+}
+
+// getOutputPath determines the path of the file to which the mock of an
+// interface declared in the given input file should be written. If nonempty,
+// outputFile is the output filename from the interface's go:mock directive.
+func getOutputPath(pkg *packages.Package, inputPath, outputFile string, options Options) (string, error) {
+	// Mocks are written alongside their interfaces, unless an output directory
+	// mirroring the module's package tree was provided. Mocks of interfaces
+	// declared in test files can't be imported, so they stay put.
+	outputDir := filepath.Dir(inputPath)
+	if options.OutputDir != "" && !isTestFile(inputPath) {
+		if pkg.Module == nil {
+			return "", fmt.Errorf("can't mirror package %s into output directory %s: no module information", pkg.PkgPath, options.OutputDir)
+		}
+		relDir, relErr := filepath.Rel(pkg.Module.Dir, outputDir)
+		if relErr != nil || !filepath.IsLocal(relDir) {
+			return "", fmt.Errorf("can't mirror package %s into output directory %s: package is outside module %s", pkg.PkgPath, options.OutputDir, pkg.Module.Path)
+		}
+		outputDir = options.OutputDir
+		if !filepath.IsAbs(outputDir) {
+			outputDir = filepath.Join(pkg.Module.Dir, outputDir)
+		}
+		outputDir = filepath.Join(outputDir, relDir)
+	}
+
+	var outputPath string
+	switch {
+	case outputFile != "":
+		outputPath = filepath.Join(outputDir, outputFile)
+	case options.OutputFile != "":
+		outputPath = filepath.Join(outputDir, options.OutputFile)
+	case isTestFile(inputPath) || options.TestOutput:
+		baseName := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(inputPath), ".go"), "_test")
+		outputPath = filepath.Join(outputDir, baseName+"_mock_test.go")
+	default:
+		outputPath = filepath.Join(outputDir, strings.TrimSuffix(filepath.Base(inputPath), ".go")+"_mock.go")
+	}
+
+	// Interfaces declared in test files are only visible to other test files,
+	// so their mocks must be test files too.
+	if isTestFile(inputPath) && !isTestFile(outputPath) {
+		return "", fmt.Errorf("interface declared in test file %s must be mocked in a _test.go file, not %s", inputPath, outputPath)
+	}
+	return outputPath, nil
+}
+
+// getOutputPackage determines the name and import path of the package to which
+// the given output file, containing mocks of interfaces declared in pkg, will
+// belong.
+func getOutputPackage(pkgs []*packages.Package, pkg *packages.Package, outputPath string) (outputPackage, error) {
+	outputDir := filepath.Dir(outputPath)
+	if len(pkg.GoFiles) > 0 && filepath.Dir(pkg.GoFiles[0]) == outputDir {
+		return outputPackage{name: pkg.Name, path: pkg.Types.Path()}, nil
+	}
+
+	// The mock will have to import pkg, which isn't possible for main packages
+	// or for declarations in test files. And a mock in another package's test
+	// file couldn't be used by anything but that package's tests.
+	switch {
+	case pkg.Name == "main":
+		return outputPackage{}, fmt.Errorf("interfaces in main package %s can't be mocked in another package", pkg.PkgPath)
+	case isTestVariant(pkg):
+		return outputPackage{}, fmt.Errorf("interfaces in test files of package %s can't be mocked in another package", pkg.PkgPath)
+	case isTestFile(outputPath):
+		return outputPackage{}, fmt.Errorf("mocks of interfaces in package %s can't be written to test file %s in another package", pkg.PkgPath, outputPath)
+	}
+
+	// If the output directory contains one of the loaded packages, the mock
+	// belongs to it. Otherwise, derive the package from the directory.
+	var outputPkg outputPackage
+	for _, other := range pkgs {
+		if !isTestVariant(other) && len(other.GoFiles) > 0 && filepath.Dir(other.GoFiles[0]) == outputDir {
+			outputPkg = outputPackage{name: other.Name, path: other.PkgPath}
+			break
+		}
+	}
+	if outputPkg.path == "" {
+		if pkg.Module == nil {
+			return outputPackage{}, fmt.Errorf("can't determine import path of output directory %s: no module information", outputDir)
+		}
+		relDir, relErr := filepath.Rel(pkg.Module.Dir, outputDir)
+		if relErr != nil || !filepath.IsLocal(relDir) {
+			return outputPackage{}, fmt.Errorf("output directory %s is outside module %s", outputDir, pkg.Module.Path)
+		}
+		// The directory may contain a package that wasn't loaded, whose
+		// package clause takes precedence over the directory's name.
+		name, nameErr := dirPackageName(outputDir)
+		if nameErr != nil {
+			return outputPackage{}, nameErr
+		}
+		outputPkg = outputPackage{
+			name: name,
+			path: path.Join(pkg.Module.Path, filepath.ToSlash(relDir)),
+		}
+	}
+
+	return outputPkg, nil
+}
+
+// importGraph loads the packages with the given import paths from the given
+// directory, along with their dependencies, returning the import paths that
+// each of them imports directly. The caller's packages may have been loaded
+// without their dependencies, so their imports can't be followed.
+func importGraph(dir string, importPaths []string) (map[string][]string, error) {
+	pkgs, loadErr := packages.Load(&packages.Config{
+		Dir:  dir,
+		Mode: packages.NeedName | packages.NeedImports | packages.NeedDeps,
+	}, importPaths...)
+	if loadErr != nil {
+		return nil, fmt.Errorf("loading imports: %w", loadErr)
+	}
+	graph := map[string][]string{}
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		graph[pkg.PkgPath] = slices.Sorted(maps.Keys(pkg.Imports))
+	})
+	return graph, nil
+}
+
+// importsPackage reports whether the package with the given import path
+// imports the other, directly or indirectly, according to the given graph.
+func importsPackage(graph map[string][]string, importer, imported string) bool {
+	visited := map[string]bool{}
+	var visit func(importPath string) bool
+	visit = func(importPath string) bool {
+		if importPath == imported {
+			return true
+		}
+		if visited[importPath] {
+			return false
+		}
+		visited[importPath] = true
+		return slices.ContainsFunc(graph[importPath], visit)
+	}
+	return visit(importer)
+}
+
+// dirPackageName returns the name of the package in the given directory, from
+// the package clauses of its Go files, or, if it has none, a name derived from
+// the directory's name.
+func dirPackageName(dir string) (string, error) {
+	if _, statErr := os.Stat(dir); statErr == nil {
+		buildPkg, importErr := build.ImportDir(dir, 0)
+		var noGoErr *build.NoGoError
+		switch {
+		case importErr == nil && buildPkg.Name != "":
+			return buildPkg.Name, nil
+		case importErr != nil && !errors.As(importErr, &noGoErr):
+			return "", fmt.Errorf("can't determine package of output directory %s: %w", dir, importErr)
+		}
+	}
+	name := packageName(filepath.Base(dir))
+	if name == "" {
+		return "", fmt.Errorf("can't derive a package name from output directory %s", dir)
+	}
+	return name, nil
+}
+
+// packageName derives a package name from a directory name by lowercasing it
+// and dropping any characters that aren't valid in an identifier. It returns
+// the empty string if there's nothing left.
+func packageName(dirName string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r):
+			return unicode.ToLower(r)
+		case unicode.IsDigit(r) || r == '_':
+			return r
+		default:
+			return -1
+		}
+	}, dirName)
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		return ""
+	}
+	return name
+}
+
+// canImport reports whether the package with the given import path may import
+// the other, per Go's rule that a package whose path contains an "internal"
+// element may only be imported from within the tree rooted at the parent of
+// that element.
+func canImport(importer, imported string) bool {
+	elems := strings.Split(imported, "/")
+	for i := len(elems) - 1; i >= 0; i-- {
+		if elems[i] == "internal" {
+			parent := strings.Join(elems[:i], "/")
+			return importer == parent || strings.HasPrefix(importer, parent+"/")
+		}
+	}
+	return true
+}
+
+// unexportedName traverses a type, returning a description of the first
+// identifier it finds that can't be referenced from the package with the given
+// import path, or the empty string if it finds none.
+func unexportedName(typ types.Type, pkgPath string, visited map[types.Type]bool) string {
+	if visited[typ] {
+		return ""
+	}
+	visited[typ] = true
+
+	// accessible reports whether the given object can be referenced by name
+	// from pkgPath.
+	accessible := func(obj types.Object) bool {
+		return obj.Exported() || obj.Pkg() == nil || obj.Pkg().Path() == pkgPath
+	}
+
+	switch t := typ.(type) {
+	case *types.Named:
+		if !accessible(t.Obj()) {
+			return fmt.Sprintf("unexported type %s.%s", t.Obj().Pkg().Name(), t.Obj().Name())
+		}
+		for typeArg := range t.TypeArgs().Types() {
+			if name := unexportedName(typeArg, pkgPath, visited); name != "" {
+				return name
+			}
+		}
+		return ""
+
+	case *types.Alias:
+		if !accessible(t.Obj()) {
+			return fmt.Sprintf("unexported type %s.%s", t.Obj().Pkg().Name(), t.Obj().Name())
+		}
+		for typeArg := range t.TypeArgs().Types() {
+			if name := unexportedName(typeArg, pkgPath, visited); name != "" {
+				return name
+			}
+		}
+		return ""
+
+	case *types.Array:
+		return unexportedName(t.Elem(), pkgPath, visited)
+
+	case *types.Slice:
+		return unexportedName(t.Elem(), pkgPath, visited)
+
+	case *types.Pointer:
+		return unexportedName(t.Elem(), pkgPath, visited)
+
+	case *types.Chan:
+		return unexportedName(t.Elem(), pkgPath, visited)
+
+	case *types.Map:
+		return cmp.Or(
+			unexportedName(t.Key(), pkgPath, visited),
+			unexportedName(t.Elem(), pkgPath, visited),
+		)
+
+	case *types.Struct:
+		for field := range t.Fields() {
+			if !accessible(field) {
+				return fmt.Sprintf("unexported struct field %s", field.Name())
+			}
+			if name := unexportedName(field.Type(), pkgPath, visited); name != "" {
+				return name
+			}
+		}
+		return ""
+
+	case *types.Tuple:
+		for variable := range t.Variables() {
+			if name := unexportedName(variable.Type(), pkgPath, visited); name != "" {
+				return name
+			}
+		}
+		return ""
+
+	case *types.Signature:
+		return cmp.Or(
+			unexportedName(t.Params(), pkgPath, visited),
+			unexportedName(t.Results(), pkgPath, visited),
+		)
+
+	case *types.Interface:
+		for method := range t.ExplicitMethods() {
+			if !accessible(method) {
+				return fmt.Sprintf("unexported interface method %s", method.Name())
+			}
+			if name := unexportedName(method.Type(), pkgPath, visited); name != "" {
+				return name
+			}
+		}
+		for embedded := range t.EmbeddedTypes() {
+			if name := unexportedName(embedded, pkgPath, visited); name != "" {
+				return name
+			}
+		}
+		return ""
+
+	case *types.Union:
+		for term := range t.Terms() {
+			if name := unexportedName(term.Type(), pkgPath, visited); name != "" {
+				return name
+			}
+		}
+		return ""
+
+	default:
+		return ""
+	}
+}
+
+// StrandedMocks returns descriptions of references, from files in the given
+// packages, to mocks that the given files would define only in test files.
+// Those references will break once the files are written, unless they're from
+// the test files of the mock's own package or its external test package, which
+// are compiled along with the mock. Only references from the given packages are
+// detected.
+func StrandedMocks(pkgs []*packages.Package, filesByPath map[string]File) []string {
+	// Collect the mocks that will be test-only, keyed by import path and name.
+	type mockKey struct {
+		pkgPath string
+		name    string
+	}
+	testFileByMock := map[mockKey]string{}
+	for outputPath, file := range filesByPath {
+		if !isTestFile(outputPath) {
+			continue
+		}
+		for _, iface := range file.Interfaces {
+			testFileByMock[mockKey{file.PackagePath, iface.MockName}] = outputPath
+		}
+	}
+	if len(testFileByMock) == 0 {
+		return nil
+	}
+
+	// Search the packages for references to those mocks, other than from the
+	// files currently declaring them and from the test files that will still
+	// see them. A package's non-test files are repeated in its test variant, so
+	// de-duplicate by position.
+	type reference struct {
+		pos      token.Position
+		name     string
+		testFile string
+	}
+	var (
+		references []reference
+		seen       = map[token.Position]bool{}
+	)
+	for _, pkg := range pkgs {
+		if isTestMain(pkg) || pkg.TypesInfo == nil {
+			continue
+		}
+		for ident, object := range pkg.TypesInfo.Uses {
+			if object.Pkg() == nil {
+				continue
+			}
+			testFile, isStranded := testFileByMock[mockKey{object.Pkg().Path(), object.Name()}]
+			if !isStranded {
+				continue
+			}
+			pos := pkg.Fset.Position(ident.Pos())
+			if seen[pos] || pos.Filename == pkg.Fset.Position(object.Pos()).Filename {
+				continue
+			}
+			if isTestFile(pos.Filename) && strings.TrimSuffix(pkg.PkgPath, "_test") == object.Pkg().Path() {
+				continue
+			}
+			seen[pos] = true
+			references = append(references, reference{pos: pos, name: object.Name(), testFile: testFile})
+		}
+	}
+
+	slices.SortFunc(references, func(a, b reference) int {
+		return cmp.Or(
+			cmp.Compare(a.pos.Filename, b.pos.Filename),
+			cmp.Compare(a.pos.Offset, b.pos.Offset),
+		)
+	})
+	var stranded []string
+	for _, ref := range references {
+		stranded = append(stranded, fmt.Sprintf("%s: %s will only be defined in tests once written to %s", ref.pos, ref.name, ref.testFile))
+	}
+	return stranded
+}
+
+// CheckMockNames returns an error if any of the mocks in the given files would
+// collide with one another or with an identifier declared at package scope in
+// one of the given packages, outside of the file the mock is written to.
+// Declarations in the pruned files, which are about to be deleted, are ignored.
+//
+// A mock declares the identifiers returned by the given function, which are
+// those its template declares at package scope. If the function is nil, a mock
+// only declares its type.
+func CheckMockNames(pkgs []*packages.Package, filesByPath map[string]File, pruned []string, declarations func(File, Interface) ([]string, error)) error {
+	type declKey struct {
+		pkgPath string
+		name    string
+	}
+	type declInfo struct {
+		outputPath string
+		mockName   string
+	}
+	declared := map[declKey]declInfo{}
+	for _, outputPath := range slices.Sorted(maps.Keys(filesByPath)) {
+		file := filesByPath[outputPath]
+		for _, iface := range file.Interfaces {
+			names := []string{iface.MockName}
+			if declarations != nil {
+				var declErr error
+				names, declErr = declarations(file, iface)
+				if declErr != nil {
+					return fmt.Errorf("listing declarations of mock %s of %s in %s: %w", iface.MockName, iface.Name, outputPath, declErr)
+				}
+			}
+			for _, name := range names {
+				// Describe the identifier by its relation to the mock.
+				what := fmt.Sprintf("mock %s of %s in %s", iface.MockName, iface.Name, outputPath)
+				if name != iface.MockName {
+					what = fmt.Sprintf("%s, declared by %s,", name, what)
+				}
+
+				key := declKey{file.PackagePath, name}
+				if other, exists := declared[key]; exists {
+					if other.mockName == iface.MockName && name == iface.MockName {
+						return fmt.Errorf("%s collides with another mock of the same name in %s", what, other.outputPath)
+					}
+					return fmt.Errorf("%s collides with %s, declared by mock %s in %s", what, name, other.mockName, other.outputPath)
+				}
+				declared[key] = declInfo{outputPath: outputPath, mockName: iface.MockName}
+
+				// A package's test variant's scope includes its test files'
+				// declarations in addition to the package's own.
+				for _, pkg := range pkgs {
+					if isTestMain(pkg) || pkg.Types == nil || pkg.Types.Path() != file.PackagePath {
+						continue
+					}
+					object := pkg.Types.Scope().Lookup(name)
+					if object == nil {
+						continue
+					}
+					if pos := pkg.Fset.Position(object.Pos()); pos.Filename != outputPath && !slices.Contains(pruned, pos.Filename) {
+						return fmt.Errorf("%s collides with %s declared at %s; choose another name with the -name or -name-pattern option", what, name, pos)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
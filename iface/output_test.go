@@ -0,0 +1,420 @@
+package iface
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nicheinc/expect"
+	"github.com/nicheinc/mock/internal/testmodule"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestCanImport(t *testing.T) {
+	type testCase struct {
+		importer string
+		imported string
+		expected bool
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			actual := canImport(testCase.importer, testCase.imported)
+			expect.Equal(t, actual, testCase.expected)
+		})
+	}
+
+	run("NotInternal", testCase{
+		importer: "example.com/a",
+		imported: "example.com/b/c",
+		expected: true,
+	})
+	run("Internal/Parent", testCase{
+		importer: "example.com/a",
+		imported: "example.com/a/internal/b",
+		expected: true,
+	})
+	run("Internal/Sibling", testCase{
+		importer: "example.com/a/mocks",
+		imported: "example.com/a/internal/b",
+		expected: true,
+	})
+	run("Internal/Outside", testCase{
+		importer: "example.com/mocks/a",
+		imported: "example.com/a/internal/b",
+		expected: false,
+	})
+	run("Internal/SharedPrefix", testCase{
+		importer: "example.com/ab",
+		imported: "example.com/a/internal/b",
+		expected: false,
+	})
+	run("Internal/Nested", testCase{
+		importer: "example.com/a/internal/b",
+		imported: "example.com/a/internal/b/internal/c",
+		expected: true,
+	})
+	run("Internal/Nested/Outside", testCase{
+		importer: "example.com/a",
+		imported: "example.com/a/internal/b/internal/c",
+		expected: false,
+	})
+	run("Internal/Root", testCase{
+		importer: "example.com/a",
+		imported: "internal/b",
+		expected: false,
+	})
+}
+
+func TestPackageName(t *testing.T) {
+	type testCase struct {
+		dirName  string
+		expected string
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			actual := packageName(testCase.dirName)
+			expect.Equal(t, actual, testCase.expected)
+		})
+	}
+
+	run("Valid", testCase{
+		dirName:  "mocks",
+		expected: "mocks",
+	})
+	run("Uppercase", testCase{
+		dirName:  "Mocks",
+		expected: "mocks",
+	})
+	run("InvalidCharacters", testCase{
+		dirName:  "store-mocks.v2",
+		expected: "storemocksv2",
+	})
+	run("LeadingDigit", testCase{
+		dirName:  "2mocks",
+		expected: "",
+	})
+	run("Empty", testCase{
+		dirName:  "...",
+		expected: "",
+	})
+}
+
+func TestGetOutputPackage(t *testing.T) {
+	loaded := func(name string) *packages.Package {
+		return &packages.Package{
+			ID:      "example.com/m/" + name,
+			Name:    name,
+			PkgPath: "example.com/m/" + name,
+			GoFiles: []string{"/m/" + name + "/" + name + ".go"},
+		}
+	}
+	var (
+		p     = loaded("p")
+		other = loaded("other")
+	)
+	// The module also contains package fakes, in directory unloaded, which
+	// wasn't loaded.
+	moduleDir := testmodule.Write(t, map[string]string{
+		"unloaded/fakes.go": "package fakes\n",
+	})
+	p.Module = &packages.Module{Path: "example.com/m", Dir: moduleDir}
+
+	type testCase struct {
+		pkgs          []*packages.Package
+		outputPath    string
+		expected      outputPackage
+		expectedError string
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			actual, getErr := getOutputPackage(testCase.pkgs, p, testCase.outputPath)
+			if testCase.expectedError == "" {
+				expect.ErrorNil(t, getErr)
+				expect.Equal(t, actual, testCase.expected, cmp.AllowUnexported(outputPackage{}))
+				return
+			}
+			expect.ErrorNonNil(t, getErr)
+			expect.Equal(t, getErr.Error(), testCase.expectedError)
+		})
+	}
+
+	run("LoadedPackage", testCase{
+		pkgs:       []*packages.Package{p, other},
+		outputPath: "/m/other/p_mock.go",
+		expected:   outputPackage{name: "other", path: "example.com/m/other"},
+	})
+	run("UnloadedPackage", testCase{
+		pkgs:       []*packages.Package{p},
+		outputPath: filepath.Join(moduleDir, "unloaded", "p_mock.go"),
+		expected:   outputPackage{name: "fakes", path: "example.com/m/unloaded"},
+	})
+	run("NewPackage", testCase{
+		pkgs:       []*packages.Package{p},
+		outputPath: filepath.Join(moduleDir, "new-mocks", "p_mock.go"),
+		expected:   outputPackage{name: "newmocks", path: "example.com/m/new-mocks"},
+	})
+	run("TestFile", testCase{
+		pkgs:          []*packages.Package{p, other},
+		outputPath:    "/m/other/p_mock_test.go",
+		expectedError: "mocks of interfaces in package example.com/m/p can't be written to test file /m/other/p_mock_test.go in another package",
+	})
+	run("OutsideModule", testCase{
+		pkgs:          []*packages.Package{p},
+		outputPath:    filepath.Join(filepath.Dir(moduleDir), "mocks", "p_mock.go"),
+		expectedError: fmt.Sprintf("output directory %s is outside module example.com/m", filepath.Join(filepath.Dir(moduleDir), "mocks")),
+	})
+}
+
+func TestCheckMockNames(t *testing.T) {
+	// Package p declares Getter in p.go and NewPutterMock in helpers.go.
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for filename, source := range map[string]string{
+		"/p/p.go":       "package p\n\ntype Getter interface{ Get() int }\n\ntype GetterMock struct{}\n",
+		"/p/helpers.go": "package p\n\nfunc NewPutterMock() {}\n",
+	} {
+		file, parseErr := parser.ParseFile(fset, filename, source, 0)
+		if parseErr != nil {
+			t.Fatal(parseErr)
+		}
+		files = append(files, file)
+	}
+	var config types.Config
+	typesPkg, typeErr := config.Check("example.com/p", fset, files, nil)
+	if typeErr != nil {
+		t.Fatal(typeErr)
+	}
+	pkgs := []*packages.Package{{ID: "example.com/p", PkgPath: "example.com/p", Fset: fset, Types: typesPkg}}
+
+	// Mocks declare their types and constructors.
+	declarations := func(_ File, iface Interface) ([]string, error) {
+		return []string{iface.MockName, "New" + iface.MockName}, nil
+	}
+	mockFile := func(mockNames ...string) File {
+		file := File{Package: "p", PackagePath: "example.com/p"}
+		for _, mockName := range mockNames {
+			file.Interfaces = append(file.Interfaces, Interface{Name: "I", MockName: mockName})
+		}
+		return file
+	}
+
+	type testCase struct {
+		filesByPath   map[string]File
+		pruned        []string
+		declarations  func(File, Interface) ([]string, error)
+		expectedError string
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			checkErr := CheckMockNames(pkgs, testCase.filesByPath, testCase.pruned, testCase.declarations)
+			if testCase.expectedError == "" {
+				expect.ErrorNil(t, checkErr)
+				return
+			}
+			expect.ErrorNonNil(t, checkErr)
+			expect.Equal(t, checkErr.Error(), testCase.expectedError)
+		})
+	}
+
+	run("NoCollisions", testCase{
+		filesByPath:  map[string]File{"/p/i_mock.go": mockFile("IMock")},
+		declarations: declarations,
+	})
+	run("MockType", testCase{
+		filesByPath:   map[string]File{"/p/getter_mock.go": mockFile("GetterMock")},
+		expectedError: "mock GetterMock of I in /p/getter_mock.go collides with GetterMock declared at /p/p.go:5:6; choose another name with the -name or -name-pattern option",
+	})
+	run("MockTypeInOutputFile", testCase{
+		filesByPath: map[string]File{"/p/p.go": mockFile("GetterMock")},
+	})
+	run("Constructor", testCase{
+		filesByPath:   map[string]File{"/p/putter_mock.go": mockFile("PutterMock")},
+		declarations:  declarations,
+		expectedError: "NewPutterMock, declared by mock PutterMock of I in /p/putter_mock.go, collides with NewPutterMock declared at /p/helpers.go:3:6; choose another name with the -name or -name-pattern option",
+	})
+	run("ConstructorWithoutDeclarations", testCase{
+		filesByPath: map[string]File{"/p/putter_mock.go": mockFile("PutterMock")},
+	})
+	run("ConstructorPruned", testCase{
+		filesByPath:  map[string]File{"/p/putter_mock.go": mockFile("PutterMock")},
+		pruned:       []string{"/p/helpers.go"},
+		declarations: declarations,
+	})
+	run("SameMock", testCase{
+		filesByPath: map[string]File{
+			"/p/a_mock.go": mockFile("IMock"),
+			"/p/b_mock.go": mockFile("IMock"),
+		},
+		declarations:  declarations,
+		expectedError: "mock IMock of I in /p/b_mock.go collides with another mock of the same name in /p/a_mock.go",
+	})
+	run("OtherMocksDeclaration", testCase{
+		filesByPath: map[string]File{
+			"/p/a_mock.go": mockFile("IMock"),
+			"/p/b_mock.go": mockFile("NewIMock"),
+		},
+		declarations:  declarations,
+		expectedError: "mock NewIMock of I in /p/b_mock.go collides with NewIMock, declared by mock IMock in /p/a_mock.go",
+	})
+}
+
+func TestStrandedMocks(t *testing.T) {
+	// GetterMock, declared in /p/mock.go, is about to be written to
+	// /p/mock_test.go instead.
+	fset := token.NewFileSet()
+	check := func(path string, sources map[string]string, importer types.Importer) (*packages.Package, *types.Package) {
+		t.Helper()
+		var files []*ast.File
+		for filename, source := range sources {
+			file, parseErr := parser.ParseFile(fset, filename, source, 0)
+			if parseErr != nil {
+				t.Fatal(parseErr)
+			}
+			files = append(files, file)
+		}
+		config := types.Config{Importer: importer}
+		info := &types.Info{Uses: map[*ast.Ident]types.Object{}}
+		typesPkg, typeErr := config.Check(path, fset, files, info)
+		if typeErr != nil {
+			t.Fatal(typeErr)
+		}
+		return &packages.Package{ID: path, PkgPath: path, Fset: fset, Types: typesPkg, TypesInfo: info}, typesPkg
+	}
+	p, typesPkg := check("example.com/p", map[string]string{
+		"/p/mock.go":   "package p\n\ntype GetterMock struct{}\n",
+		"/p/uses.go":   "package p\n\nvar _ GetterMock\n",
+		"/p/p_test.go": "package p\n\nvar _ GetterMock\n",
+	}, nil)
+	importer := packageImporter{"example.com/p": typesPkg}
+	pTest, _ := check("example.com/p_test", map[string]string{
+		"/p/ext_test.go": "package p_test\n\nimport \"example.com/p\"\n\nvar _ p.GetterMock\n",
+	}, importer)
+	q, _ := check("example.com/q", map[string]string{
+		"/q/q.go":      "package q\n\nimport \"example.com/p\"\n\nvar _ p.GetterMock\n",
+		"/q/q_test.go": "package q\n\nimport \"example.com/p\"\n\nvar _ p.GetterMock\n",
+	}, importer)
+
+	type testCase struct {
+		filesByPath map[string]File
+		expected    []string
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			actual := StrandedMocks([]*packages.Package{p, pTest, q}, testCase.filesByPath)
+			expect.Equal(t, actual, testCase.expected)
+		})
+	}
+
+	run("TestFile", testCase{
+		filesByPath: map[string]File{
+			"/p/mock_test.go": {Package: "p", PackagePath: "example.com/p", Interfaces: []Interface{{Name: "Getter", MockName: "GetterMock"}}},
+		},
+		expected: []string{
+			"/p/uses.go:3:7: GetterMock will only be defined in tests once written to /p/mock_test.go",
+			"/q/q.go:5:9: GetterMock will only be defined in tests once written to /p/mock_test.go",
+			"/q/q_test.go:5:9: GetterMock will only be defined in tests once written to /p/mock_test.go",
+		},
+	})
+	run("NonTestFile", testCase{
+		filesByPath: map[string]File{
+			"/p/mock.go": {Package: "p", PackagePath: "example.com/p", Interfaces: []Interface{{Name: "Getter", MockName: "GetterMock"}}},
+		},
+	})
+}
+
+// packageImporter imports the type-checked packages it maps import paths to.
+type packageImporter map[string]*types.Package
+
+func (i packageImporter) Import(path string) (*types.Package, error) {
+	if pkg, ok := i[path]; ok {
+		return pkg, nil
+	}
+	return nil, fmt.Errorf("package %s not found", path)
+}
+
+func TestDirPackageName(t *testing.T) {
+	dir := t.TempDir()
+	for name, contents := range map[string]string{
+		"named/fakes.go":        "package fakes\n",
+		"named/fakes_test.go":   "package fakes_test\n",
+		"multiple/a.go":         "package a\n",
+		"multiple/b.go":         "package b\n",
+		"empty/README.md":       "",
+		"123/README.md":         "",
+		"excluded/excluded.go":  "//go:build never\n\npackage excluded\n",
+		"testonly/mock_test.go": "package testonly\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if mkdirErr := os.MkdirAll(filepath.Dir(path), 0o755); mkdirErr != nil {
+			t.Fatal(mkdirErr)
+		}
+		if writeErr := os.WriteFile(path, []byte(contents), 0o644); writeErr != nil {
+			t.Fatal(writeErr)
+		}
+	}
+
+	type testCase struct {
+		dir           string
+		expected      string
+		expectedError string
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			actual, nameErr := dirPackageName(filepath.Join(dir, testCase.dir))
+			if testCase.expectedError == "" {
+				expect.ErrorNil(t, nameErr)
+				expect.Equal(t, actual, testCase.expected)
+				return
+			}
+			expect.ErrorNonNil(t, nameErr)
+			expect.Equal(t, nameErr.Error(), testCase.expectedError)
+		})
+	}
+
+	run("PackageClause", testCase{
+		dir:      "named",
+		expected: "fakes",
+	})
+	run("TestFilesOnly", testCase{
+		dir:      "testonly",
+		expected: "testonly",
+	})
+	run("NoGoFiles", testCase{
+		dir:      "empty",
+		expected: "empty",
+	})
+	run("Excluded", testCase{
+		dir:      "excluded",
+		expected: "excluded",
+	})
+	run("Missing", testCase{
+		dir:      "missing",
+		expected: "missing",
+	})
+	run("MultiplePackages", testCase{
+		dir:           "multiple",
+		expectedError: "can't determine package of output directory " + filepath.Join(dir, "multiple") + ": found packages a (a.go) and b (b.go) in " + filepath.Join(dir, "multiple"),
+	})
+	run("InvalidName", testCase{
+		dir:           "123",
+		expectedError: "can't derive a package name from output directory " + filepath.Join(dir, "123"),
+	})
+}
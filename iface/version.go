@@ -0,0 +1,54 @@
+package iface
+
+import (
+	"fmt"
+	"go/build/constraint"
+	"go/types"
+	"go/version"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Minimum Go versions of the language features that mocks may require.
+const (
+	genericsVersion     = "go1.18"
+	genericAliasVersion = "go1.24"
+)
+
+// fileGoVersion returns the Go language version of a mock file of interfaces in
+// the given package, e.g. "go1.21": that of the package's module, raised to the
+// minimum version implied by the file's build constraint, if any. If the
+// module's version is unknown, it's the constraint's minimum version, or the
+// empty string if there's none.
+func fileGoVersion(pkg *packages.Package, buildConstraint constraint.Expr) string {
+	var goVersion string
+	if pkg.Module != nil && pkg.Module.GoVersion != "" {
+		goVersion = version.Lang("go" + pkg.Module.GoVersion)
+	}
+	if buildConstraint != nil {
+		if minVersion := constraint.GoVersion(buildConstraint); minVersion != "" && (goVersion == "" || version.Compare(minVersion, goVersion) > 0) {
+			goVersion = minVersion
+		}
+	}
+	return goVersion
+}
+
+// checkGoVersion returns an error if the given interface type can't be mocked
+// in the given Go language version, which is ignored if empty.
+func checkGoVersion(object types.Object, goVersion string) error {
+	if goVersion == "" {
+		return nil
+	}
+	typeParams := getTypeParams(object.Type())
+	if typeParams == nil || typeParams.Len() == 0 {
+		return nil
+	}
+	kind, minVersion := "generic interface", genericsVersion
+	if _, isAlias := object.Type().(*types.Alias); isAlias {
+		kind, minVersion = "generic alias", genericAliasVersion
+	}
+	if version.Compare(goVersion, minVersion) < 0 {
+		return fmt.Errorf("%s %s requires %s or later, but its mock targets %s", kind, object.Name(), minVersion, goVersion)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package iface
+
+import (
+	"go/ast"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/nicheinc/expect"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestFileGoVersion(t *testing.T) {
+	type testCase struct {
+		module          *packages.Module
+		buildConstraint string
+		expected        string
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			var expr constraint.Expr
+			if testCase.buildConstraint != "" {
+				var parseErr error
+				expr, parseErr = constraint.Parse("//go:build " + testCase.buildConstraint)
+				expect.ErrorNil(t, parseErr)
+			}
+			actual := fileGoVersion(&packages.Package{Module: testCase.module}, expr)
+			expect.Equal(t, actual, testCase.expected)
+		})
+	}
+
+	run("NoModule", testCase{
+		expected: "",
+	})
+	run("NoVersion", testCase{
+		module:   &packages.Module{Path: "example.com/store"},
+		expected: "",
+	})
+	run("Module", testCase{
+		module:   &packages.Module{GoVersion: "1.21.3"},
+		expected: "go1.21",
+	})
+	run("LowerConstraint", testCase{
+		module:          &packages.Module{GoVersion: "1.21"},
+		buildConstraint: "go1.18 && linux",
+		expected:        "go1.21",
+	})
+	run("HigherConstraint", testCase{
+		module:          &packages.Module{GoVersion: "1.17"},
+		buildConstraint: "go1.23 && linux",
+		expected:        "go1.23",
+	})
+	run("ConstraintWithoutModule", testCase{
+		buildConstraint: "go1.23",
+		expected:        "go1.23",
+	})
+	run("ConstraintWithoutVersion", testCase{
+		module:          &packages.Module{Path: "example.com/store"},
+		buildConstraint: "go1.21 && linux",
+		expected:        "go1.21",
+	})
+	run("ConstraintWithoutGoVersion", testCase{
+		buildConstraint: "linux",
+		expected:        "",
+	})
+}
+
+func TestCheckGoVersion(t *testing.T) {
+	const source = `package store
+
+type Store interface{ Get() int }
+
+type Getter[V any] interface{ Get() V }
+
+type IntGetter = Getter[int]
+
+type AliasGetter[V any] = Getter[V]
+`
+	fset := token.NewFileSet()
+	file, parseErr := parser.ParseFile(fset, "store.go", source, 0)
+	if parseErr != nil {
+		t.Fatal(parseErr)
+	}
+	config := types.Config{GoVersion: "go1.24"}
+	pkg, typeErr := config.Check("example.com/store", fset, []*ast.File{file}, nil)
+	if typeErr != nil {
+		t.Fatal(typeErr)
+	}
+
+	type testCase struct {
+		name          string
+		goVersion     string
+		expectedError string
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			checkErr := checkGoVersion(pkg.Scope().Lookup(testCase.name), testCase.goVersion)
+			if testCase.expectedError == "" {
+				expect.ErrorNil(t, checkErr)
+				return
+			}
+			expect.ErrorNonNil(t, checkErr)
+			expect.Equal(t, checkErr.Error(), testCase.expectedError)
+		})
+	}
+
+	run("NotGeneric", testCase{
+		name:      "Store",
+		goVersion: "go1.17",
+	})
+	run("UnknownVersion", testCase{
+		name:      "AliasGetter",
+		goVersion: "",
+	})
+	run("Generic", testCase{
+		name:      "Getter",
+		goVersion: "go1.18",
+	})
+	run("Generic/TooOld", testCase{
+		name:          "Getter",
+		goVersion:     "go1.17",
+		expectedError: "generic interface Getter requires go1.18 or later, but its mock targets go1.17",
+	})
+	run("Alias", testCase{
+		name:      "IntGetter",
+		goVersion: "go1.18",
+	})
+	run("GenericAlias", testCase{
+		name:      "AliasGetter",
+		goVersion: "go1.24",
+	})
+	run("GenericAlias/TooOld", testCase{
+		name:          "AliasGetter",
+		goVersion:     "go1.23",
+		expectedError: "generic alias AliasGetter requires go1.24 or later, but its mock targets go1.23",
+	})
+}
@@ -0,0 +1,141 @@
+// Package configfile reads the optional mock.json configuration files at the
+// roots of modules, which configure the mocks of their interfaces.
+package configfile
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/nicheinc/mock/iface"
+	"github.com/nicheinc/mock/render"
+)
+
+// Name is the name of the optional configuration file at the root of a module.
+const Name = "mock.json"
+
+// File represents the contents of a configuration file. Its options apply to
+// the whole module, except where overridden for particular directories.
+type File struct {
+	Options
+	// Module-relative directories whose go:mock directives are ignored. A
+	// trailing "/..." matches subdirectories too.
+	Exclude []string `json:"exclude"`
+	// Options overriding the module-wide options, keyed by module-relative
+	// directory. They also apply to subdirectories.
+	Dirs map[string]Options `json:"dirs"`
+}
+
+// Options represents the options that can be set by a configuration file. Nil
+// fields are unset.
+type Options struct {
+	OutputFile  *string `json:"outputFile"`
+	OutputDir   *string `json:"outputDir"`
+	Test        *bool   `json:"test"`
+	Tags        *string `json:"tags"`
+	Lenient     *bool   `json:"lenient"`
+	NamePattern *string `json:"namePattern"`
+	Style       *string `json:"style"`
+	// Custom template, relative to the module's root
+	Template *string `json:"template"`
+}
+
+// Apply overrides the given options with the Options' set fields, resolving
+// paths relative to the given module directory.
+func (o Options) Apply(moduleDir string, options *iface.Options) {
+	if o.OutputFile != nil {
+		options.OutputFile = *o.OutputFile
+	}
+	if o.OutputDir != nil {
+		options.OutputDir = *o.OutputDir
+	}
+	if o.Test != nil {
+		options.TestOutput = *o.Test
+	}
+	if o.Tags != nil {
+		options.Tags = *o.Tags
+	}
+	if o.Lenient != nil {
+		options.Lenient = *o.Lenient
+	}
+	if o.NamePattern != nil {
+		options.NamePattern = *o.NamePattern
+	}
+	if o.Style != nil {
+		options.Style = cmp.Or(*o.Style, render.DefaultStyle)
+	}
+	if o.Template != nil {
+		options.Template = *o.Template
+		if options.Template != "" && !filepath.IsAbs(options.Template) {
+			options.Template = filepath.Join(moduleDir, options.Template)
+		}
+	}
+}
+
+// Read reads the configuration file in the given module directory. If there's
+// no such file, it returns an empty configuration.
+func Read(moduleDir string) (File, error) {
+	var (
+		path = filepath.Join(moduleDir, Name)
+		file File
+	)
+	contents, readErr := os.ReadFile(path)
+	if errors.Is(readErr, fs.ErrNotExist) {
+		return file, nil
+	} else if readErr != nil {
+		return file, fmt.Errorf("reading %s: %w", path, readErr)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.DisallowUnknownFields()
+	if decodeErr := decoder.Decode(&file); decodeErr != nil {
+		return file, fmt.Errorf("parsing %s: %w", path, decodeErr)
+	}
+	return file, nil
+}
+
+// Excluded reports whether the given module-relative directory is excluded.
+func (f File) Excluded(dir string) bool {
+	for _, pattern := range f.Exclude {
+		pattern = filepath.ToSlash(filepath.Clean(pattern))
+		if subtree, isSubtree := strings.CutSuffix(pattern, "/..."); isSubtree && withinDir(dir, subtree) {
+			return true
+		}
+		if dir == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply overrides the given options with those configured for the given
+// module-relative directory of the given module: first the module-wide options,
+// then the directory's ancestors' options, and finally the directory's own.
+func (f File) Apply(moduleDir, dir string, options *iface.Options) {
+	f.Options.Apply(moduleDir, options)
+	var dirs []string
+	for overrideDir := range f.Dirs {
+		if withinDir(dir, filepath.ToSlash(filepath.Clean(overrideDir))) {
+			dirs = append(dirs, overrideDir)
+		}
+	}
+	// Shorter paths are ancestors of longer ones.
+	slices.SortFunc(dirs, func(a, b string) int {
+		return len(filepath.Clean(a)) - len(filepath.Clean(b))
+	})
+	for _, overrideDir := range dirs {
+		f.Dirs[overrideDir].Apply(moduleDir, options)
+	}
+}
+
+// withinDir reports whether the slash-separated relative path is the given
+// directory or one of its subdirectories.
+func withinDir(path, dir string) bool {
+	return dir == "." || path == dir || strings.HasPrefix(path, dir+"/")
+}
@@ -0,0 +1,117 @@
+package configfile
+
+import (
+	"testing"
+
+	"github.com/nicheinc/expect"
+	"github.com/nicheinc/mock/iface"
+)
+
+func TestFileApply(t *testing.T) {
+	var (
+		yes       = true
+		no        = false
+		mocksFile = "mocks.go"
+		tags      = "integration"
+		template  = "templates/mock.tmpl"
+		absolute  = "/templates/mock.tmpl"
+		file      = File{
+			Options: Options{Lenient: &yes},
+			Dirs: map[string]Options{
+				"a":     {Test: &yes},
+				"a/b/":  {Lenient: &no, Tags: &tags},
+				"a/b/c": {OutputFile: &mocksFile},
+				"t":     {Template: &template},
+				"t/abs": {Template: &absolute},
+			},
+		}
+	)
+	type testCase struct {
+		dir      string
+		expected iface.Options
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			var actual iface.Options
+			file.Apply("/module", testCase.dir, &actual)
+			expect.Equal(t, actual, testCase.expected)
+		})
+	}
+
+	run("Root", testCase{
+		dir:      ".",
+		expected: iface.Options{Lenient: true},
+	})
+	run("NoOverrides", testCase{
+		dir:      "z",
+		expected: iface.Options{Lenient: true},
+	})
+	run("SharedPrefix", testCase{
+		dir:      "ab",
+		expected: iface.Options{Lenient: true},
+	})
+	run("Override", testCase{
+		dir:      "a",
+		expected: iface.Options{Lenient: true, TestOutput: true},
+	})
+	run("NestedOverrides", testCase{
+		dir:      "a/b/c",
+		expected: iface.Options{TestOutput: true, Tags: "integration", OutputFile: "mocks.go"},
+	})
+	run("InheritedOverrides", testCase{
+		dir:      "a/b/d",
+		expected: iface.Options{TestOutput: true, Tags: "integration"},
+	})
+	run("Template/Relative", testCase{
+		dir:      "t",
+		expected: iface.Options{Lenient: true, Template: "/module/templates/mock.tmpl"},
+	})
+	run("Template/Absolute", testCase{
+		dir:      "t/abs",
+		expected: iface.Options{Lenient: true, Template: "/templates/mock.tmpl"},
+	})
+}
+
+func TestFileExcluded(t *testing.T) {
+	file := File{
+		Exclude: []string{"legacy/...", "tools"},
+	}
+	type testCase struct {
+		dir      string
+		expected bool
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			expect.Equal(t, file.Excluded(testCase.dir), testCase.expected)
+		})
+	}
+
+	run("Subtree/Root", testCase{
+		dir:      "legacy",
+		expected: true,
+	})
+	run("Subtree/Subdirectory", testCase{
+		dir:      "legacy/store",
+		expected: true,
+	})
+	run("Subtree/SharedPrefix", testCase{
+		dir:      "legacystore",
+		expected: false,
+	})
+	run("Exact", testCase{
+		dir:      "tools",
+		expected: true,
+	})
+	run("Exact/Subdirectory", testCase{
+		dir:      "tools/gen",
+		expected: false,
+	})
+	run("NotExcluded", testCase{
+		dir:      "store",
+		expected: false,
+	})
+}
@@ -0,0 +1,198 @@
+// Package diff produces line-based unified diffs.
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// context is the number of unchanged lines shown around each change.
+const context = 3
+
+// op is a single line of an edit script: kept (' '), deleted ('-') or inserted
+// ('+').
+type op struct {
+	kind byte
+	line string
+}
+
+// Unified returns a unified diff transforming old, labeled oldName, into new,
+// labeled newName. It returns nil if the contents are identical.
+func Unified(oldName, newName string, old, new []byte) []byte {
+	if bytes.Equal(old, new) {
+		return nil
+	}
+	ops := edits(splitLines(old), splitLines(new))
+
+	out := &bytes.Buffer{}
+	fmt.Fprintf(out, "--- %s\n+++ %s\n", oldName, newName)
+	var (
+		oldLine int // Number of old lines before ops[i]
+		newLine int // Number of new lines before ops[i]
+	)
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+
+		// Start the hunk with up to context kept lines before the change.
+		start := max(i-context, 0)
+		for j := start; j < i; j++ {
+			oldLine--
+			newLine--
+		}
+
+		// Extend the hunk until a run of kept lines is long enough to separate
+		// it from the next change.
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j + 1
+			} else if j-end >= 2*context {
+				break
+			}
+		}
+		end = min(end+context, len(ops))
+
+		var oldCount, newCount int
+		for _, op := range ops[start:end] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(oldLine, oldCount), hunkRange(newLine, newCount))
+		for _, op := range ops[start:end] {
+			out.WriteByte(op.kind)
+			out.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		oldLine += oldCount
+		newLine += newCount
+		i = end
+	}
+	return out.Bytes()
+}
+
+// hunkRange formats the range of a hunk's lines, given the number of lines
+// before the hunk and the number of lines in it.
+func hunkRange(before, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", before)
+	case 1:
+		return fmt.Sprintf("%d", before+1)
+	default:
+		return fmt.Sprintf("%d,%d", before+1, count)
+	}
+}
+
+// splitLines splits the contents into lines, keeping their line endings.
+func splitLines(contents []byte) []string {
+	var lines []string
+	for len(contents) > 0 {
+		end := bytes.IndexByte(contents, '\n') + 1
+		if end == 0 {
+			end = len(contents)
+		}
+		lines = append(lines, string(contents[:end]))
+		contents = contents[end:]
+	}
+	return lines
+}
+
+// edits returns a shortest edit script transforming a into b, using Myers'
+// algorithm.
+func edits(a, b []string) []op {
+	// Lines shared at the start and end needn't go through the search.
+	var prefix, suffix int
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []op
+	for _, line := range a[:prefix] {
+		ops = append(ops, op{' ', line})
+	}
+	ops = append(ops, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, op{' ', line})
+	}
+	return ops
+}
+
+// myers returns a shortest edit script transforming a into b. For each number
+// of edits d, it records the furthest-reaching path along each diagonal k,
+// then backtracks through those records from the end.
+func myers(a, b []string) []op {
+	var (
+		n, m  = len(a), len(b)
+		v     = map[int]int{1: 0}
+		trace []map[int]int
+	)
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, maps.Clone(v))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[k-1] < v[k+1]) {
+				x = v[k+1]
+			} else {
+				x = v[k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	var ops []op
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		previous := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && previous[k-1] < previous[k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := previous[prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			ops = append(ops, op{' ', a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, op{'+', b[y-1]})
+			} else {
+				ops = append(ops, op{'-', a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
@@ -0,0 +1,66 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/nicheinc/expect"
+)
+
+func TestUnified(t *testing.T) {
+	type testCase struct {
+		old      string
+		new      string
+		expected string
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			actual := Unified("old.go", "new.go", []byte(testCase.old), []byte(testCase.new))
+			expect.Equal(t, string(actual), testCase.expected)
+		})
+	}
+
+	run("Identical", testCase{
+		old:      "a\nb\n",
+		new:      "a\nb\n",
+		expected: "",
+	})
+	run("Created", testCase{
+		old: "",
+		new: "a\nb\n",
+		expected: "--- old.go\n+++ new.go\n" +
+			"@@ -0,0 +1,2 @@\n+a\n+b\n",
+	})
+	run("Deleted", testCase{
+		old: "a\n",
+		new: "",
+		expected: "--- old.go\n+++ new.go\n" +
+			"@@ -1 +0,0 @@\n-a\n",
+	})
+	run("Changed", testCase{
+		old: "a\nb\nc\nd\ne\nf\ng\nh\n",
+		new: "a\nb\nc\nd\nE\nf\ng\nh\n",
+		expected: "--- old.go\n+++ new.go\n" +
+			"@@ -2,7 +2,7 @@\n b\n c\n d\n-e\n+E\n f\n g\n h\n",
+	})
+	run("SeparateHunks", testCase{
+		old: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+		new: "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n12\n",
+		expected: "--- old.go\n+++ new.go\n" +
+			"@@ -1,3 +1,4 @@\n+0\n 1\n 2\n 3\n" +
+			"@@ -8,5 +9,4 @@\n 8\n 9\n 10\n-11\n 12\n",
+	})
+	run("MergedHunks", testCase{
+		old: "1\n2\n3\n4\n5\n6\n7\n8\n",
+		new: "0\n1\n2\n3\n4\n5\n6\n8\n",
+		expected: "--- old.go\n+++ new.go\n" +
+			"@@ -1,8 +1,8 @@\n+0\n 1\n 2\n 3\n 4\n 5\n 6\n-7\n 8\n",
+	})
+	run("NoTrailingNewline", testCase{
+		old: "a\nb",
+		new: "a\nb\n",
+		expected: "--- old.go\n+++ new.go\n" +
+			"@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+	})
+}
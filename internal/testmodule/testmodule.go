@@ -0,0 +1,43 @@
+// Package testmodule writes Go modules for tests to load.
+package testmodule
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// GoMod is the go.mod file of the modules written by Write, unless the files
+// given to it include one.
+const GoMod = "module example.com/m\n\ngo 1.24\n"
+
+// Write writes a module in a new temporary directory with the given files,
+// keyed by slash-separated paths relative to the module's root, and returns the
+// directory, with any symbolic links in its path resolved so that it matches
+// the paths of the loaded packages' files.
+func Write(t testing.TB, files map[string]string) string {
+	t.Helper()
+	dir, evalErr := filepath.EvalSymlinks(t.TempDir())
+	if evalErr != nil {
+		t.Fatal(evalErr)
+	}
+	if _, hasGoMod := files["go.mod"]; !hasGoMod {
+		WriteFile(t, filepath.Join(dir, "go.mod"), GoMod)
+	}
+	for name, contents := range files {
+		WriteFile(t, filepath.Join(dir, filepath.FromSlash(name)), contents)
+	}
+	return dir
+}
+
+// WriteFile writes the given contents to the file at the given path, creating
+// its directory if necessary.
+func WriteFile(t testing.TB, path, contents string) {
+	t.Helper()
+	if mkdirErr := os.MkdirAll(filepath.Dir(path), 0o755); mkdirErr != nil {
+		t.Fatal(mkdirErr)
+	}
+	if writeErr := os.WriteFile(path, []byte(contents), 0o644); writeErr != nil {
+		t.Fatal(writeErr)
+	}
+}
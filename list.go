@@ -0,0 +1,77 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/nicheinc/mock/iface"
+)
+
+// listedInterface describes a mocked interface for the -list option.
+type listedInterface struct {
+	Name        string `json:"name"`
+	PackagePath string `json:"packagePath"`
+	Position    string `json:"position"`
+	OutputPath  string `json:"outputPath"`
+	MockName    string `json:"mockName"`
+	Methods     int    `json:"methods"`
+	TypeParams  string `json:"typeParams,omitempty"`
+}
+
+// listInterfaces returns descriptions of the interfaces in the given mock files,
+// sorted by package and then by position.
+func listInterfaces(filesByPath map[string]iface.File) []listedInterface {
+	type mocked struct {
+		iface      iface.Interface
+		outputPath string
+	}
+	var all []mocked
+	for outputPath, file := range filesByPath {
+		for _, i := range file.Interfaces {
+			all = append(all, mocked{iface: i, outputPath: outputPath})
+		}
+	}
+	slices.SortFunc(all, func(a, b mocked) int {
+		return cmp.Or(
+			cmp.Compare(a.iface.PackagePath, b.iface.PackagePath),
+			cmp.Compare(a.iface.Position.Filename, b.iface.Position.Filename),
+			cmp.Compare(a.iface.Position.Offset, b.iface.Position.Offset),
+		)
+	})
+
+	listed := []listedInterface{}
+	for _, m := range all {
+		position := m.iface.Position
+		position.Filename = displayPath(position.Filename)
+		listed = append(listed, listedInterface{
+			Name:        m.iface.Name,
+			PackagePath: m.iface.PackagePath,
+			Position:    position.String(),
+			OutputPath:  displayPath(m.outputPath),
+			MockName:    m.iface.MockName,
+			Methods:     len(m.iface.Methods),
+			TypeParams:  m.iface.TypeParams.String(),
+		})
+	}
+	return listed
+}
+
+// printInterfaces prints the listed interfaces as a JSON array or, otherwise, as
+// a table.
+func printInterfaces(w io.Writer, listed []listedInterface, asJSON bool) error {
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "\t")
+		return encoder.Encode(listed)
+	}
+	table := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(table, "INTERFACE\tPACKAGE\tPOSITION\tOUTPUT\tMETHODS\tTYPE PARAMS")
+	for _, i := range listed {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%d\t%s\n", i.Name, i.PackagePath, i.Position, i.OutputPath, i.Methods, i.TypeParams)
+	}
+	return table.Flush()
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"go/token"
+	"testing"
+
+	"github.com/nicheinc/expect"
+	"github.com/nicheinc/mock/iface"
+)
+
+func TestListInterfaces(t *testing.T) {
+	filesByPath := map[string]iface.File{
+		"/tmp/b/b_mock.go": {
+			Interfaces: []iface.Interface{{
+				Name:        "B",
+				MockName:    "BMock",
+				PackagePath: "example.com/b",
+				Position:    token.Position{Filename: "/tmp/b/b.go", Offset: 10, Line: 2, Column: 6},
+			}},
+		},
+		"/tmp/a/mocks.go": {
+			Interfaces: []iface.Interface{
+				{
+					Name:        "Second",
+					MockName:    "SecondMock",
+					PackagePath: "example.com/a",
+					Position:    token.Position{Filename: "/tmp/a/a.go", Offset: 90, Line: 9, Column: 6},
+				},
+				{
+					Name:        "First",
+					MockName:    "FirstMock",
+					PackagePath: "example.com/a",
+					Position:    token.Position{Filename: "/tmp/a/a.go", Offset: 40, Line: 4, Column: 6},
+					Methods:     iface.Methods{{Name: "Get"}, {Name: "Set"}},
+					TypeParams:  iface.TypeParams{{Name: "T", Constraint: "any"}},
+				},
+			},
+		},
+	}
+	listed := listInterfaces(filesByPath)
+	expect.Equal(t, listed, []listedInterface{
+		{
+			Name:        "First",
+			PackagePath: "example.com/a",
+			Position:    "/tmp/a/a.go:4:6",
+			OutputPath:  "/tmp/a/mocks.go",
+			MockName:    "FirstMock",
+			Methods:     2,
+			TypeParams:  "[T any]",
+		},
+		{
+			Name:        "Second",
+			PackagePath: "example.com/a",
+			Position:    "/tmp/a/a.go:9:6",
+			OutputPath:  "/tmp/a/mocks.go",
+			MockName:    "SecondMock",
+		},
+		{
+			Name:        "B",
+			PackagePath: "example.com/b",
+			Position:    "/tmp/b/b.go:2:6",
+			OutputPath:  "/tmp/b/b_mock.go",
+			MockName:    "BMock",
+		},
+	})
+
+	buf := &bytes.Buffer{}
+	expect.ErrorNil(t, printInterfaces(buf, listInterfaces(nil), true))
+	expect.Equal(t, buf.String(), "[]\n")
+}
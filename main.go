@@ -1,129 +1,517 @@
 package main
 
 import (
-	"bytes"
-	_ "embed"
+	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"maps"
 	"os"
-	"text/template"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
 
+	"github.com/nicheinc/mock/generate"
 	"github.com/nicheinc/mock/iface"
+	"github.com/nicheinc/mock/render"
 	"golang.org/x/tools/go/packages"
-	"golang.org/x/tools/imports"
 )
 
-//go:embed template.tmpl
-var tmpl string
-
 const helpMessage = `Usage: %s [options] [interface]
 
 When the positional interface argument is omitted, all interfaces in the search
-directory annotated with a "go:mock [output file]" directive will be mocked and
-output to stdout or, with the -w option, written to files. If a go:mock
-directive in a file called example.go doesn't specify an output file, the
-default output file will be the -o flag (if provided) or else example_mock.go.
+directory annotated with a "go:mock [options] [output file]" directive will be
+mocked and output to stdout or, with the -w option, written to files. If a
+go:mock directive in a file called example.go doesn't specify an output file,
+the default output file will be the -o flag (if provided) or else
+example_mock.go (or, with the -test option, example_mock_test.go). Each output
+file inherits the build constraints of its interfaces' files, along with any
+extra constraints given by -tags. The -w option only writes files whose
+contents have changed, then prints a summary to stderr. If any mock can't be
+generated, all the errors are reported and no files are written.
+
+Mocks target the Go language version of their module's go directive, or of
+their build constraints if later: generic interfaces can't be mocked before Go
+1.18, nor generic aliases before Go 1.24.
+
+A go:mock directive's options override the corresponding flags below for a
+single interface: -o (or the positional output file), -tags, -test, -lenient,
+-only, -exclude, -name, -name-pattern, -style, and -template. Quoted option
+values use Go syntax, e.g. -tags "linux && amd64".
+
+The -style option selects a built-in template: "minimal" mocks have a stub and
+a call counter for each method, "recorder" mocks also record each call's
+arguments, "fluent" mocks are configured with chained expectations, e.g.
+m.ExpectGet("key").Return(1), which are asserted when the test finishes, and
+"moq" and "gomock" mocks have the same APIs as those generated by moq and
+mockgen, respectively. The latter use this module's gomock package.
+
+The -template option renders mocks with a custom text/template file layered
+over the style's template, which may redefine any of its named templates
+("header", "mock", "struct", "assertion", "method", "imports", and so on), or
+replace it altogether with text outside of definitions. A go:mock directive's
+template is relative to the directive's file.
+
+The -list option lists the annotated interfaces, with their packages, positions,
+output files, method counts, and type parameters, without generating mocks. With
+-json, it prints a JSON array of objects instead of a table.
 
 When an interface name is provided as a positional argument after all other
 flags, only that interface will be mocked. The -w option is incompatible with an
 interface argument.
 
+The -check option compares each mock with the existing file at its output path,
+exiting with a non-zero status and listing the files that are stale or missing
+if any differ, without writing anything. Like gofmt -l, the -l option lists the
+files whose contents would change, without affecting the exit status, and the
+-diff option prints unified diffs of the changes instead of writing them.
+
+Generated files start with a "Code generated by mock ... DO NOT EDIT." header.
+The -prune option deletes files with that header in the searched packages that
+no go:mock directive produces any longer, or, with -check, -l, or -diff, reports
+them. Files generated from source files outside the searched packages are kept,
+since their directives weren't searched. Mocks of an interface provided as an argument, e.g. by go:generate, name
+the interface in their headers ("Code generated by mock for Getter from ...")
+and are never pruned.
+
+The -watch option generates all mocks in the packages matching a pattern, such
+as ./..., then polls the packages' files, along with those of their
+dependencies in the main module, regenerating the mocks of the packages affected
+by each change. It prints errors rather than exiting, and runs until
+interrupted.
+
+A go:mock output file may include a directory, relative to the interface's
+directory. Mocks written to a directory other than the interface's own belong to
+the package in that directory, as do mocks written under the -out-dir directory,
+which mirrors the module's package tree.
+
+Defaults for the options below may be set in a mock.json file at the root of the
+module. Options given on the command line take precedence over the file, and
+go:mock directive options take precedence over both.
+
 Options:
 `
 
 type config struct {
 	dir        string
 	outputFile string
+	outputDir  string
+	testOutput bool
+	tags       string
+	lenient    bool
+	only       iface.MethodList
+	exclude    iface.MethodList
+	mockName   string
+	pattern    string
 	write      bool
+	check      bool
+	list       bool
+	diff       bool
+	prune      bool
+	jobs       int
+	watch      string
+	listIfaces bool
+	json       bool
+	emitIR     bool
+	render     string
+	style      string
+	template   string
+
+	// Templates loaded so far
+	templates *render.Cache
+	// Names of the flags set on the command line
+	setFlags map[string]bool
 }
 
 func main() {
 	var config config
 	flag.StringVar(&config.dir, "d", ".", "Directory to search for interfaces in")
 	flag.StringVar(&config.outputFile, "o", "", "Output file (default stdout)")
+	flag.StringVar(&config.outputDir, "out-dir", "", "Directory mirroring the module's package tree to write mocks to (default alongside interfaces)")
+	flag.BoolVar(&config.testOutput, "test", false, "Write mocks to _mock_test.go files by default rather than _mock.go files")
+	flag.StringVar(&config.tags, "tags", "", "Extra build constraint expression for mock files")
+	flag.BoolVar(&config.lenient, "lenient", false, "Return zero values rather than panicking when stubs are nil")
+	flag.Var(&config.only, "only", "Comma-separated `methods` to stub, embedding the interface to provide the rest")
+	flag.Var(&config.exclude, "exclude", "Comma-separated `methods` not to stub, embedding the interface to provide them")
+	flag.StringVar(&config.mockName, "name", "", "Name of the mock type (default from -name-pattern)")
+	flag.StringVar(&config.pattern, "name-pattern", "", "Pattern for mock type names, where %s is the interface name (default \"%sMock\")")
 	flag.BoolVar(&config.write, "w", false, "Write mocks to files rather than stdout")
+	flag.BoolVar(&config.check, "check", false, "Exit with a non-zero status if any mock files are stale or missing, without writing them")
+	flag.BoolVar(&config.list, "l", false, "List mock files that are stale or missing")
+	flag.BoolVar(&config.diff, "diff", false, "Print unified diffs of changes to mock files rather than writing them")
+	flag.BoolVar(&config.prune, "prune", false, "Delete generated mock files that no go:mock directive produces")
+	flag.IntVar(&config.jobs, "j", runtime.GOMAXPROCS(0), "Maximum number of mock files to generate concurrently")
+	flag.StringVar(&config.watch, "watch", "", "Package `pattern` to watch, regenerating mocks as files change")
+	flag.BoolVar(&config.listIfaces, "list", false, "List the annotated interfaces and their output files rather than generating mocks")
+	flag.BoolVar(&config.json, "json", false, "With -list, print JSON rather than a table")
+	flag.BoolVar(&config.emitIR, "emit-ir", false, "Print the intermediate representation of the mocks as JSON rather than rendering them")
+	flag.StringVar(&config.render, "render", "", "Render mocks from an intermediate representation `file` (- for stdin) rather than loading packages")
+	flag.StringVar(&config.style, "style", render.DefaultStyle, "Built-in `style` of mocks: "+strings.Join(render.Styles, ", "))
+	flag.StringVar(&config.template, "template", "", "Custom template `file` for mocks (default built-in template)")
 
 	flag.Usage = func() {
 		fmt.Fprintf(flag.CommandLine.Output(), helpMessage, os.Args[0])
 		flag.PrintDefaults()
 	}
 	flag.Parse()
+	config.setFlags = map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		config.setFlags[f.Name] = true
+	})
 
-	// Load package info.
-	pkgs, packageErr := packages.Load(&packages.Config{Mode: packages.LoadSyntax}, config.dir)
-	if packageErr != nil {
-		log.Fatalf(`Error loading package information: %s`, packageErr)
+	if config.testOutput && config.outputDir != "" {
+		log.Fatalf("The -test and -out-dir options are mutually exclusive")
+	}
+	if config.check && config.write {
+		log.Fatalf("The -check and -w options are mutually exclusive")
+	}
+	if config.diff && config.write {
+		log.Fatalf("The -diff and -w options are mutually exclusive")
+	}
+	if config.jobs < 1 {
+		log.Fatalf("The -j option must be at least 1")
+	}
+	if config.prune && !config.write && !config.check && !config.list && !config.diff && config.watch == "" {
+		log.Fatalf("The -prune option requires one of the -w, -check, -l, -diff, or -watch options")
+	}
+
+	if config.json && !config.listIfaces {
+		log.Fatalf("The -json option requires the -list option")
+	}
+	if config.listIfaces && (config.write || config.check || config.list || config.diff || config.prune || config.watch != "") {
+		log.Fatalf("The -list option is incompatible with the -w, -check, -l, -diff, -prune, and -watch options")
+	}
+
+	if config.emitIR && (config.write || config.check || config.list || config.diff || config.prune || config.watch != "") {
+		log.Fatalf("The -emit-ir option is incompatible with the -w, -check, -l, -diff, -prune, and -watch options")
+	}
+	if config.render != "" && config.watch != "" {
+		log.Fatalf("The -render and -watch options are mutually exclusive")
+	}
+
+	// Load templates lazily, since they may be configured per file. Relative
+	// paths would be ambiguous once go:mock directives' templates are resolved
+	// relative to their files.
+	config.templates = render.NewCache()
+	if !slices.Contains(render.Styles, config.style) {
+		log.Fatalf("Unknown style %q (expected one of %s)", config.style, strings.Join(render.Styles, ", "))
+	}
+	if config.template != "" {
+		template, absErr := filepath.Abs(config.template)
+		if absErr != nil {
+			log.Fatalf("Error resolving template: %s", absErr)
+		}
+		config.template = template
+		if _, loadErr := config.templates.Load(config.style, config.template); loadErr != nil {
+			log.Fatalf("Error loading template: %s", loadErr)
+		}
 	}
-	if len(pkgs) < 1 {
-		log.Fatalf(`No packages found in %s`, config.dir)
+
+	// Watch mode generates all mocks, then regenerates them as files change
+	// until interrupted.
+	if config.watch != "" {
+		switch {
+		case len(flag.Args()) > 0:
+			log.Fatalf("The -watch option is only permitted when generating all mocks")
+		case config.setFlags["d"]:
+			log.Fatalf("The -watch and -d options are mutually exclusive")
+		case config.check || config.list || config.diff:
+			log.Fatalf("The -watch option is incompatible with the -check, -l, and -diff options")
+		}
+		config.write = true
+		config.watchPackages()
+		return
 	}
 
-	filesByPath := func() map[string]iface.File {
-		// The presence/absence of a positional argument determines whether
-		// we're generating mocks for all interfaces annotated with "go:mock" or
-		// for a single interface.
-		if len(flag.Args()) < 1 {
-			// Search all packages in the target directory for interfaces
-			// annotated with "go:mock".
-			filesByPath, getErr := iface.GetAllInterfaces(pkgs, config.outputFile)
+	var (
+		filesByPath map[string]iface.File
+		orphaned    []string
+	)
+	if config.render != "" {
+		// Render mocks from an intermediate representation rather than
+		// loading packages.
+		switch {
+		case len(flag.Args()) > 0:
+			log.Fatalf("The -render option is only permitted when generating all mocks")
+		case config.setFlags["d"] || config.prune || config.listIfaces || config.emitIR || config.setFlags["style"] || config.template != "":
+			log.Fatalf("The -render option is incompatible with the -d, -prune, -list, -emit-ir, -style, and -template options")
+		}
+		var readErr error
+		filesByPath, readErr = readIRFile(config.render)
+		if readErr != nil {
+			log.Fatalf("Error reading intermediate representation: %s", readErr)
+		}
+	} else {
+		// Load package info.
+		pkgs, packageErr := config.generator(config.dir).LoadPackages(context.Background())
+		if packageErr != nil {
+			log.Fatalf("Error loading packages: %s", packageErr)
+		}
+		if len(pkgs) < 1 {
+			log.Fatalf(`No packages found in %s`, config.dir)
+		}
+
+		// The presence/absence of a positional argument determines whether we're
+		// generating mocks for all interfaces annotated with "go:mock" or for a
+		// single interface.
+		if config.listIfaces {
+			if len(flag.Args()) > 0 {
+				log.Fatalf("The -list option is only permitted when generating all mocks")
+			}
+			filesByPath, getErr := config.generator().Interfaces(pkgs)
+			if getErr != nil {
+				fatalErrors("Error getting interface information", getErr)
+			}
+			if printErr := printInterfaces(os.Stdout, listInterfaces(filesByPath), config.json); printErr != nil {
+				log.Fatalf("Error listing interfaces: %s", printErr)
+			}
+			return
+		} else if len(flag.Args()) < 1 {
+			var getErr error
+			filesByPath, orphaned, getErr = config.getAllInterfaces(pkgs, pkgs)
 			if getErr != nil {
-				log.Fatalf(`Error getting interface information: %s`, getErr)
+				fatalErrors("Error finding mocks", getErr)
 			}
-			return filesByPath
 		} else {
 			if config.write {
 				log.Fatalf("The -w option is only permitted when generating all mocks")
 			}
-			config.write = config.outputFile != ""
-
-			// The first positional argument is the interface name. In this
-			// case, the target directory must contain a single package.
-			if len(pkgs) > 1 {
-				log.Fatalf(`Found more than one package in %s`, config.dir)
+			if config.outputDir != "" {
+				log.Fatalf("The -out-dir option is only permitted when generating all mocks")
+			}
+			if config.testOutput {
+				log.Fatalf("The -test option is only permitted when generating all mocks")
+			}
+			if config.prune {
+				log.Fatalf("The -prune option is only permitted when generating all mocks")
 			}
-			// Search the package for info about the interface.
-			file, getErr := iface.GetInterface(pkgs[0], flag.Args()[0])
+			if (config.check || config.list || config.diff) && config.outputFile == "" {
+				log.Fatalf("The -check, -l and -diff options require an output file when mocking a single interface")
+			}
+			config.write = config.outputFile != "" && !config.check && !config.list && !config.diff
+
+			// The first positional argument is the interface name. In this case,
+			// the target directory must contain a single package (along with its
+			// tests). Search the package for info about the interface.
+			file, getErr := config.generator().Interface(pkgs, flag.Args()[0])
 			if getErr != nil {
-				log.Fatalf("Error getting interface information: %s", getErr)
+				fatalErrors("Error getting interface information", getErr)
+			}
+			if config.write {
+				outputPath, absErr := filepath.Abs(config.outputFile)
+				if absErr != nil {
+					log.Fatalf("Error resolving output file: %s", absErr)
+				}
+				if checkErr := iface.CheckMockNames(pkgs, map[string]iface.File{outputPath: file}, nil, config.templates.Declarations); checkErr != nil {
+					log.Fatalf("Error naming mocks: %s", checkErr)
+				}
 			}
-			return map[string]iface.File{config.outputFile: file}
+			filesByPath = map[string]iface.File{config.outputFile: file}
 		}
-	}()
-
-	// Parse the template
-	tmpl, templateErr := template.New("default").Parse(tmpl)
-	if templateErr != nil {
-		log.Fatalf("Error parsing template: %s", templateErr)
 	}
 
-	for outputPath, file := range filesByPath {
-		// Execute/output the template for this interface.
-		buf := &bytes.Buffer{}
-		if executeErr := tmpl.Execute(buf, file); executeErr != nil {
-			log.Fatalf("Error executing template: %s", executeErr)
+	// Print the intermediate representation rather than rendering mocks, if
+	// requested.
+	if config.emitIR {
+		if emitErr := writeIR(os.Stdout, filesByPath); emitErr != nil {
+			log.Fatalf("Error writing intermediate representation: %s", emitErr)
 		}
+		return
+	}
+
+	// Render each mock in memory.
+	rendered, renderErr := config.generator().Render(context.Background(), filesByPath)
+	if renderErr != nil {
+		fatalErrors("Error rendering mocks", renderErr)
+	}
 
-		// Format it with go imports.
-		formatted, importsErr := imports.Process(outputPath, buf.Bytes(), nil)
-		if importsErr != nil {
-			log.Fatalf("Error formatting output: %s", importsErr)
+	// Without -w, mocks are printed to stdout unless they're only to be
+	// compared with the existing files.
+	if !config.write && !config.check && !config.list && !config.diff {
+		for _, outputPath := range slices.Sorted(maps.Keys(rendered)) {
+			if _, writeErr := os.Stdout.Write(rendered[outputPath]); writeErr != nil {
+				log.Fatalf("Error writing to stdout: %s", writeErr)
+			}
 		}
+		return
+	}
 
-		// Open the output file, if provided, or use stdout.
-		out := os.Stdout
-		if config.write {
-			var createErr error
-			out, createErr = os.Create(outputPath)
-			if createErr != nil {
-				log.Fatalf("Error creating output file: %s", createErr)
+	// Compare the mocks with the existing files.
+	stale, missing, staleErr := staleFiles(rendered)
+	if staleErr != nil {
+		log.Fatalf("Error checking mock files: %s", staleErr)
+	}
+	changed := slices.Sorted(slices.Values(slices.Concat(stale, missing)))
+	for _, outputPath := range slices.Sorted(slices.Values(slices.Concat(changed, orphaned))) {
+		if config.list {
+			fmt.Println(displayPath(outputPath))
+		}
+		if config.diff {
+			fileDiff, diffErr := diffFile(outputPath, rendered[outputPath])
+			if diffErr != nil {
+				log.Fatalf("Error diffing mock files: %s", diffErr)
+			}
+			if _, writeErr := os.Stdout.Write(fileDiff); writeErr != nil {
+				log.Fatalf("Error writing to stdout: %s", writeErr)
 			}
-			defer out.Close()
 		}
+	}
+	if config.check {
+		if !config.list && !config.diff {
+			for _, outputPath := range stale {
+				fmt.Printf("%s: stale\n", displayPath(outputPath))
+			}
+			for _, outputPath := range missing {
+				fmt.Printf("%s: missing\n", displayPath(outputPath))
+			}
+			for _, outputPath := range orphaned {
+				fmt.Printf("%s: orphaned\n", displayPath(outputPath))
+			}
+		}
+		if len(changed) > 0 || len(orphaned) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+	if !config.write {
+		return
+	}
 
-		// Write the formatted output to the file.
-		if _, writeErr := out.Write(formatted); writeErr != nil {
-			log.Fatalf("Error writing to file: %s", writeErr)
+	if writeErr := writeFiles(rendered, changed, orphaned); writeErr != nil {
+		fatalErrors("Error writing mocks", writeErr)
+	}
+	config.printSummary(len(changed), len(rendered)-len(changed), len(orphaned))
+}
+
+// generator returns the configuration for generating mocks of the interfaces in
+// the packages matching the given patterns, with the options given by the
+// command line flags.
+func (c config) generator(patterns ...string) generate.Config {
+	return generate.Config{
+		Patterns:  patterns,
+		Style:     c.style,
+		Options:   c.overrideOptions,
+		Templates: c.templates,
+		Jobs:      c.jobs,
+		Version:   version(),
+	}
+}
+
+// getAllInterfaces searches the given packages, except those excluded by
+// configuration files, for interfaces annotated with "go:mock", returning their
+// mock files by output path. With -prune, it also returns the paths of the mock
+// files in the searched packages that are no longer produced. The remaining
+// packages are those whose declarations the mocks mustn't collide with. Errors
+// getting the interfaces are returned as is, so they keep their positions.
+func (c config) getAllInterfaces(pkgs, searchPkgs []*packages.Package) (map[string]iface.File, []string, error) {
+	generator := c.generator()
+	filesByPath, getErr := generator.Interfaces(searchPkgs)
+	if getErr != nil {
+		return nil, nil, getErr
+	}
+
+	// Find the mock files no longer produced by any directive, whose
+	// declarations can't collide with the mocks replacing them.
+	var orphaned []string
+	if c.prune {
+		searched, searchErr := generator.Searched(searchPkgs)
+		if searchErr != nil {
+			return nil, nil, searchErr
+		}
+		var orphanedErr error
+		orphaned, orphanedErr = orphanedFiles(searched, filesByPath)
+		if orphanedErr != nil {
+			return nil, nil, fmt.Errorf("finding mock files to prune: %w", orphanedErr)
 		}
 	}
+	if checkErr := iface.CheckMockNames(pkgs, filesByPath, orphaned, c.templates.Declarations); checkErr != nil {
+		return nil, nil, fmt.Errorf("naming mocks: %w", checkErr)
+	}
+
+	// Warn about code that will break when mocks move to test files.
+	for _, stranded := range iface.StrandedMocks(pkgs, filesByPath) {
+		log.Printf("Warning: %s", stranded)
+	}
+	return filesByPath, orphaned, nil
+}
+
+// writeFiles writes the changed mock files, leaving the rest untouched so as not
+// to invalidate build caches or trigger file watchers, then deletes the
+// orphaned ones.
+func writeFiles(rendered map[string][]byte, changed, orphaned []string) error {
+	files := map[string][]byte{}
+	for _, outputPath := range changed {
+		files[outputPath] = rendered[outputPath]
+	}
+	return generate.WriteFiles(files, orphaned)
+}
+
+// fatalErrors logs the given error after the message and exits. If it joins
+// several errors, such as those of iface.GetAllInterfaces, each is printed on a
+// line of its own, so that their positions start their lines for editors and CI
+// annotations.
+func fatalErrors(message string, err error) {
+	logErrors(message, err)
+	os.Exit(1)
+}
+
+// logErrors logs the given error after the message, printing each of the
+// errors it joins, if any, on a line of its own.
+func logErrors(message string, err error) {
+	joined, isJoined := err.(interface{ Unwrap() []error })
+	if !isJoined {
+		log.Printf("%s: %s", message, err)
+		return
+	}
+	log.Print(message + ":")
+	for _, joinedErr := range joined.Unwrap() {
+		fmt.Fprintln(log.Writer(), joinedErr)
+	}
+}
+
+// printSummary prints the numbers of mock files written, unchanged, and (with
+// -prune) removed to stderr.
+func (c config) printSummary(written, unchanged, removed int) {
+	fmt.Fprintf(os.Stderr, "%s written, %d unchanged", plural(written, "mock file"), unchanged)
+	if c.prune {
+		fmt.Fprintf(os.Stderr, ", %d removed", removed)
+	}
+	fmt.Fprintln(os.Stderr)
+}
+
+// plural formats a count of the given noun, pluralizing the noun if necessary.
+func plural(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// writeIR writes the intermediate representation of the given mock files, with
+// their output paths relative to the working directory where possible.
+func writeIR(w io.Writer, filesByPath map[string]iface.File) error {
+	relFilesByPath := map[string]iface.File{}
+	for outputPath, file := range filesByPath {
+		if file.Template != "" {
+			file.Template = displayPath(file.Template)
+		}
+		relFilesByPath[displayPath(outputPath)] = file
+	}
+	return iface.WriteIR(w, relFilesByPath)
+}
+
+// readIRFile reads the intermediate representation in the given file, or stdin
+// if the path is "-".
+func readIRFile(path string) (map[string]iface.File, error) {
+	if path == "-" {
+		return iface.ReadIR(os.Stdin)
+	}
+	file, openErr := os.Open(path)
+	if openErr != nil {
+		return nil, openErr
+	}
+	defer file.Close()
+	return iface.ReadIR(file)
 }
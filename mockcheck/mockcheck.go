@@ -0,0 +1,279 @@
+// Package mockcheck defines an Analyzer that checks go:mock directives and the
+// mocks generated from them.
+//
+// It reports directives that can't be mocked, such as those on types that
+// aren't interfaces, mock files that are missing or out of date, and mocks that
+// no longer satisfy their interfaces. For a mock file in the analyzed package
+// that's out of date, it suggests the regenerated contents as a fix.
+//
+// The analyzer applies the options of the directives and of the module's
+// mock.json configuration file, but not those passed to mock on the command
+// line, so it only suits modules whose mocks are generated by "mock -w" alone.
+package mockcheck
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/nicheinc/mock/iface"
+	"github.com/nicheinc/mock/internal/configfile"
+	"github.com/nicheinc/mock/render"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+const doc = `check go:mock directives and the mocks generated from them
+
+The mockcheck analyzer reports go:mock directives that can't be mocked, mock
+files that are missing or out of date, and mocks that no longer satisfy their
+interfaces. Regenerating an out-of-date mock file is suggested as a fix.`
+
+// Analyzer checks go:mock directives and the mocks generated from them.
+var Analyzer = &analysis.Analyzer{
+	Name: "mockcheck",
+	Doc:  doc,
+	URL:  "https://pkg.go.dev/github.com/nicheinc/mock/mockcheck",
+	// Mocks that no longer satisfy their interfaces typically fail to compile.
+	RunDespiteErrors: true,
+	Run:              run,
+}
+
+// templates caches the templates loaded by every pass.
+var templates = render.NewCache()
+
+// headerVersion matches the version of mock in a generated file's header.
+var headerVersion = regexp.MustCompile(`^// Code generated by mock (v\S+) from `)
+
+func run(pass *analysis.Pass) (any, error) {
+	if len(pass.Files) == 0 {
+		return nil, nil
+	}
+	pkg := newPackage(pass)
+
+	// Apply the module's configuration file, unless it excludes the package.
+	options := iface.Options{Style: render.DefaultStyle, TemplateImports: templates.Imports}
+	if pkg.Module != nil {
+		config, readErr := configfile.Read(pkg.Module.Dir)
+		if readErr != nil {
+			return nil, readErr
+		}
+		if dir, relErr := filepath.Rel(pkg.Module.Dir, pkg.Dir); relErr == nil && filepath.IsLocal(dir) {
+			dir = filepath.ToSlash(dir)
+			if config.Excluded(dir) {
+				return nil, nil
+			}
+			config.Apply(pkg.Module.Dir, dir, &options)
+		}
+	}
+
+	filesByPath, getErr := iface.GetAllInterfaces([]*packages.Package{pkg}, func(*packages.Package) iface.Options { return options }, 1)
+	if getErr != nil {
+		return nil, reportErrors(pass, getErr)
+	}
+
+	for _, outputPath := range slices.Sorted(maps.Keys(filesByPath)) {
+		file := filesByPath[outputPath]
+		if len(file.Interfaces) == 0 {
+			continue
+		}
+		pos := position(pass, file.Interfaces[0].Position)
+		existing, readErr := os.ReadFile(outputPath)
+		if errors.Is(readErr, fs.ErrNotExist) {
+			pass.Reportf(pos, "mock file %s is missing", filepath.Base(outputPath))
+			continue
+		} else if readErr != nil {
+			return nil, readErr
+		}
+
+		// Differences in mock's version alone don't make a mock out of date.
+		header, _, _ := bytes.Cut(existing, []byte("\n"))
+		if match := headerVersion.FindSubmatch(header); match != nil {
+			file.Version = string(match[1])
+		}
+		tmpl, loadErr := templates.Load(file.Style, file.Template)
+		if loadErr != nil {
+			return nil, loadErr
+		}
+		rendered, renderErr := tmpl.Render(outputPath, file)
+		if renderErr != nil {
+			pass.Reportf(pos, "rendering mock file %s: %v", filepath.Base(outputPath), renderErr)
+			continue
+		}
+		if !bytes.Equal(existing, rendered) {
+			diagnostic := analysis.Diagnostic{
+				Pos:     pos,
+				Message: fmt.Sprintf("mock file %s is out of date", filepath.Base(outputPath)),
+			}
+			if tokenFile := packageFile(pass, outputPath); tokenFile != nil {
+				diagnostic.SuggestedFixes = []analysis.SuggestedFix{{
+					Message: fmt.Sprintf("Regenerate %s", filepath.Base(outputPath)),
+					TextEdits: []analysis.TextEdit{{
+						Pos:     tokenFile.Pos(0),
+						End:     tokenFile.Pos(tokenFile.Size()),
+						NewText: rendered,
+					}},
+				}}
+			}
+			pass.Report(diagnostic)
+		}
+
+		// Mocks in the analyzed package can be checked against their
+		// interfaces.
+		if packageFile(pass, outputPath) != nil {
+			for _, ifaceInfo := range file.Interfaces {
+				checkMock(pass, ifaceInfo)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// newPackage describes the analyzed package in the form expected by the iface
+// package.
+func newPackage(pass *analysis.Pass) *packages.Package {
+	pkg := &packages.Package{
+		ID:        pass.Pkg.Path(),
+		Name:      pass.Pkg.Name(),
+		PkgPath:   pass.Pkg.Path(),
+		Fset:      pass.Fset,
+		Syntax:    pass.Files,
+		Types:     pass.Pkg,
+		TypesInfo: pass.TypesInfo,
+		Imports:   map[string]*packages.Package{},
+	}
+	// Test variants aren't distinguished from their packages, since go vet
+	// only analyzes the test variant of a package with tests. Other drivers
+	// report the diagnostics common to both once.
+	for _, file := range pass.Files {
+		pkg.GoFiles = append(pkg.GoFiles, pass.Fset.File(file.Pos()).Name())
+	}
+	pkg.Dir = filepath.Dir(pkg.GoFiles[0])
+	for _, imported := range pass.Pkg.Imports() {
+		pkg.Imports[imported.Path()] = &packages.Package{
+			ID:      imported.Path(),
+			Name:    imported.Name(),
+			PkgPath: imported.Path(),
+			Types:   imported,
+		}
+	}
+	if pass.Module != nil {
+		if moduleDir := findModuleDir(pkg.Dir); moduleDir != "" {
+			pkg.Module = &packages.Module{
+				Path:      pass.Module.Path,
+				Dir:       moduleDir,
+				GoVersion: strings.TrimPrefix(pass.Module.GoVersion, "go"),
+			}
+		}
+	}
+	return pkg
+}
+
+// findModuleDir returns the closest directory containing a go.mod file among
+// the given directory and its ancestors, or the empty string if there's none.
+func findModuleDir(dir string) string {
+	for {
+		if _, statErr := os.Stat(filepath.Join(dir, "go.mod")); statErr == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// reportErrors reports each of the errors joined in the given error, which
+// are attributed to the go:mock directives responsible for them, except for
+// type errors, which are left to the compiler. It returns any error that isn't
+// attributed to a position.
+func reportErrors(pass *analysis.Pass, err error) error {
+	if joined, isJoined := err.(interface{ Unwrap() []error }); isJoined {
+		var errs []error
+		for _, joinedErr := range joined.Unwrap() {
+			errs = append(errs, reportErrors(pass, joinedErr))
+		}
+		return errors.Join(errs...)
+	}
+	var typeErrs *iface.TypeErrors
+	if errors.As(err, &typeErrs) {
+		return nil
+	}
+	var positionErr *iface.PositionError
+	if !errors.As(err, &positionErr) {
+		return err
+	}
+	pass.Reportf(position(pass, positionErr.Pos), "%v", positionErr.Err)
+	return nil
+}
+
+// checkMock reports the mock of the given interface if it no longer satisfies
+// the interface, both being declared in the analyzed package.
+func checkMock(pass *analysis.Pass, ifaceInfo iface.Interface) {
+	mockObj, isMockType := pass.Pkg.Scope().Lookup(ifaceInfo.MockName).(*types.TypeName)
+	ifaceObj, isIfaceType := pass.Pkg.Scope().Lookup(ifaceInfo.Name).(*types.TypeName)
+	if !isMockType || !isIfaceType {
+		return
+	}
+	ifaceType, isNamed := ifaceObj.Type().(*types.Named)
+	if !isNamed {
+		return
+	}
+	ifaceUnderlying, isInterface := ifaceType.Underlying().(*types.Interface)
+	if !isInterface {
+		return
+	}
+
+	// Generic mocks are instantiated with their interfaces' type parameters,
+	// in terms of which the interfaces' methods are declared.
+	var mockType types.Type = mockObj.Type()
+	if typeParams := ifaceType.TypeParams(); typeParams.Len() > 0 {
+		typeArgs := make([]types.Type, typeParams.Len())
+		for i := range typeArgs {
+			typeArgs[i] = typeParams.At(i)
+		}
+		var instantiateErr error
+		mockType, instantiateErr = types.Instantiate(nil, mockType, typeArgs, false)
+		if instantiateErr != nil {
+			return
+		}
+	}
+
+	if method, wrongType := types.MissingMethod(types.NewPointer(mockType), ifaceUnderlying, true); method != nil {
+		problem := "is missing method"
+		if wrongType {
+			problem = "has the wrong type for method"
+		}
+		pass.Reportf(mockObj.Pos(), "mock %s no longer satisfies %s: it %s %s", ifaceInfo.MockName, ifaceInfo.Name, problem, method.Name())
+	}
+}
+
+// position returns the position in the analyzed package corresponding to the
+// given one, or token.NoPos if it's outside the package's files.
+func position(pass *analysis.Pass, pos token.Position) token.Pos {
+	if tokenFile := packageFile(pass, pos.Filename); tokenFile != nil && pos.Offset <= tokenFile.Size() {
+		return tokenFile.Pos(pos.Offset)
+	}
+	return token.NoPos
+}
+
+// packageFile returns the file of the analyzed package with the given name, if
+// there is one.
+func packageFile(pass *analysis.Pass, filename string) *token.File {
+	for _, file := range pass.Files {
+		if tokenFile := pass.Fset.File(file.Pos()); tokenFile.Name() == filename {
+			return tokenFile
+		}
+	}
+	return nil
+}
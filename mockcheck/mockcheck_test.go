@@ -0,0 +1,156 @@
+package mockcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nicheinc/expect"
+	"golang.org/x/tools/go/analysis"
+)
+
+// analyze runs the analyzer on the package in the given directory, returning
+// its diagnostics and the file set of their positions.
+func analyze(t *testing.T, dir string) ([]analysis.Diagnostic, *token.FileSet) {
+	t.Helper()
+	var (
+		fset  = token.NewFileSet()
+		files []*ast.File
+	)
+	paths, globErr := filepath.Glob(filepath.Join(dir, "*.go"))
+	if globErr != nil {
+		t.Fatal(globErr)
+	}
+	for _, path := range paths {
+		file, parseErr := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if parseErr != nil {
+			t.Fatal(parseErr)
+		}
+		files = append(files, file)
+	}
+	var (
+		info = &types.Info{
+			Types:      map[ast.Expr]types.TypeAndValue{},
+			Defs:       map[*ast.Ident]types.Object{},
+			Uses:       map[*ast.Ident]types.Object{},
+			Implicits:  map[ast.Node]types.Object{},
+			Selections: map[*ast.SelectorExpr]*types.Selection{},
+			Scopes:     map[ast.Node]*types.Scope{},
+		}
+		// Like the analyzer, ignore type errors in mocks.
+		config = types.Config{
+			Importer: importer.ForCompiler(fset, "source", nil),
+			Error:    func(error) {},
+		}
+		diagnostics []analysis.Diagnostic
+	)
+	pkg, _ := config.Check("example.com/p", fset, files, info)
+	pass := &analysis.Pass{
+		Analyzer:  Analyzer,
+		Fset:      fset,
+		Files:     files,
+		Pkg:       pkg,
+		TypesInfo: info,
+		Report: func(diagnostic analysis.Diagnostic) {
+			diagnostics = append(diagnostics, diagnostic)
+		},
+	}
+	if _, runErr := Analyzer.Run(pass); runErr != nil {
+		t.Fatal(runErr)
+	}
+	return diagnostics, fset
+}
+
+func TestAnalyzer(t *testing.T) {
+	type testCase struct {
+		// Files of package p, by name
+		files    map[string]string
+		expected []string
+		// Whether a fix is expected to regenerate store_mock.go, after which
+		// there should be no diagnostics
+		fixExpected bool
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			dir := t.TempDir()
+			for name, contents := range testCase.files {
+				if writeErr := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644); writeErr != nil {
+					t.Fatal(writeErr)
+				}
+			}
+			diagnostics, fset := analyze(t, dir)
+
+			var (
+				actual []string
+				fixes  []analysis.SuggestedFix
+			)
+			for _, diagnostic := range diagnostics {
+				position := fset.Position(diagnostic.Pos)
+				actual = append(actual, fmt.Sprintf("%s:%d: %s", filepath.Base(position.Filename), position.Line, diagnostic.Message))
+				fixes = append(fixes, diagnostic.SuggestedFixes...)
+			}
+			expect.Equal(t, actual, testCase.expected)
+
+			expect.Equal(t, len(fixes) == 1, testCase.fixExpected)
+			if len(fixes) == 1 {
+				edit := fixes[0].TextEdits[0]
+				if writeErr := os.WriteFile(filepath.Join(dir, "store_mock.go"), edit.NewText, 0o644); writeErr != nil {
+					t.Fatal(writeErr)
+				}
+				diagnostics, _ := analyze(t, dir)
+				expect.Equal(t, len(diagnostics), 0)
+			}
+		})
+	}
+
+	const source = `package p
+
+//go:mock
+type Store interface {
+	Get(key string) int
+}
+`
+
+	run("Missing", testCase{
+		files: map[string]string{
+			"store.go": source,
+		},
+		expected: []string{"store.go:4: mock file store_mock.go is missing"},
+	})
+	run("OutOfDate", testCase{
+		files: map[string]string{
+			"store.go":      source,
+			"store_mock.go": "// Code generated by mock v1.0.0 from store.go. DO NOT EDIT.\n\npackage p\n\ntype StoreMock struct{}\n\nfunc (m *StoreMock) Get(key string) int { return 0 }\n",
+		},
+		expected:    []string{"store.go:4: mock file store_mock.go is out of date"},
+		fixExpected: true,
+	})
+	run("Unsatisfied", testCase{
+		files: map[string]string{
+			"store.go":      source,
+			"store_mock.go": "package p\n\ntype StoreMock struct{}\n\nfunc (m *StoreMock) Get(key string) string { return \"\" }\n",
+		},
+		expected: []string{
+			"store.go:4: mock file store_mock.go is out of date",
+			"store_mock.go:3: mock StoreMock no longer satisfies Store: it has the wrong type for method Get",
+		},
+		fixExpected: true,
+	})
+	run("NotInterface", testCase{
+		files: map[string]string{
+			"store.go": "package p\n\n//go:mock\ntype Store struct{}\n\n//go:mock -bogus\ntype Getter interface{}\n",
+		},
+		expected: []string{
+			"store.go:6: invalid go:mock directive: flag provided but not defined: -bogus",
+			"store.go:3: Store is not an interface type",
+		},
+	})
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime/debug"
+	"slices"
+	"strings"
+
+	"github.com/nicheinc/mock/iface"
+	"golang.org/x/tools/go/packages"
+)
+
+// generatedHeader matches the first line of the files generated by mock for
+// go:mock directives, capturing their source files. It doesn't match the
+// headers of mocks of interfaces named on the command line, e.g. by go:generate
+// directives, which name the interface (e.g. "Code generated by mock for Getter
+// from getter.go"), so they're never pruned.
+var generatedHeader = regexp.MustCompile(`^// Code generated by mock( \S+)? from (.*)\. DO NOT EDIT\.$`)
+
+// version returns the version of mock recorded in generated files' headers, or
+// the empty string if it's unknown (e.g. in development builds).
+func version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Version == "" || info.Main.Version == "(devel)" {
+		return ""
+	}
+	return info.Main.Version
+}
+
+// orphanedFiles returns, in sorted order, the paths of the files in the given
+// packages that were generated by mock but aren't among the given mock files,
+// meaning that no go:mock directive produces them any longer. Since directives
+// may write mocks to other packages, only files whose source files all belong
+// to the given packages' directories are considered, so that the mocks of
+// directives that weren't searched are kept.
+func orphanedFiles(pkgs []*packages.Package, filesByPath map[string]iface.File) ([]string, error) {
+	dirs := map[string]bool{}
+	for _, pkg := range pkgs {
+		for _, path := range slices.Concat(pkg.GoFiles, pkg.IgnoredFiles) {
+			dirs[filepath.Dir(path)] = true
+		}
+	}
+
+	var orphaned []string
+	for _, pkg := range pkgs {
+		// Test mains' files are generated by the go command, not by mock.
+		if strings.HasSuffix(pkg.ID, ".test") {
+			continue
+		}
+		// Mocks whose build constraints exclude them from the current build
+		// are among the ignored files.
+		for _, path := range slices.Concat(pkg.GoFiles, pkg.IgnoredFiles) {
+			if _, produced := filesByPath[path]; produced || !strings.HasSuffix(path, ".go") || slices.Contains(orphaned, path) {
+				continue
+			}
+			sources, readErr := generatedFrom(path)
+			if readErr != nil {
+				return nil, readErr
+			}
+			if len(sources) > 0 && !slices.ContainsFunc(sources, func(source string) bool { return !dirs[filepath.Dir(source)] }) {
+				orphaned = append(orphaned, path)
+			}
+		}
+	}
+	slices.Sort(orphaned)
+	return orphaned, nil
+}
+
+// generatedFrom returns the paths of the source files named in the header of
+// the file at the given path, if it starts with the header of a file generated
+// by mock for go:mock directives, or nil otherwise.
+func generatedFrom(path string) ([]string, error) {
+	file, openErr := os.Open(path)
+	if errors.Is(openErr, fs.ErrNotExist) {
+		return nil, nil
+	} else if openErr != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, openErr)
+	}
+	defer file.Close()
+	line, readErr := bufio.NewReader(file).ReadString('\n')
+	if readErr != nil && !errors.Is(readErr, io.EOF) {
+		return nil, fmt.Errorf("reading %s: %w", path, readErr)
+	}
+	match := generatedHeader.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+	if match == nil {
+		return nil, nil
+	}
+	var sources []string
+	for _, source := range strings.Split(match[2], ", ") {
+		sources = append(sources, filepath.Join(filepath.Dir(path), filepath.FromSlash(source)))
+	}
+	return sources, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/nicheinc/expect"
+	"github.com/nicheinc/mock/iface"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestGeneratedFrom(t *testing.T) {
+	dir := t.TempDir()
+	type testCase struct {
+		contents string
+		expected []string
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			path := filepath.Join(dir, name+".go")
+			if writeErr := os.WriteFile(path, []byte(testCase.contents), 0o644); writeErr != nil {
+				t.Fatal(writeErr)
+			}
+			actual, err := generatedFrom(path)
+			expect.ErrorNil(t, err)
+			expect.Equal(t, actual, testCase.expected)
+		})
+	}
+
+	run("Header", testCase{
+		contents: "// Code generated by mock from store.go. DO NOT EDIT.\n\npackage store\n",
+		expected: []string{filepath.Join(dir, "store.go")},
+	})
+	run("HeaderWithVersion", testCase{
+		contents: "// Code generated by mock v1.2.3 from a.go, b.go. DO NOT EDIT.\n\npackage store\n",
+		expected: []string{filepath.Join(dir, "a.go"), filepath.Join(dir, "b.go")},
+	})
+	run("OtherDirectory", testCase{
+		contents: "// Code generated by mock from ../remote.go. DO NOT EDIT.\n\npackage mocks\n",
+		expected: []string{filepath.Join(filepath.Dir(dir), "remote.go")},
+	})
+	run("Standalone", testCase{
+		contents: "// Code generated by mock v1.2.3 for Store from store.go. DO NOT EDIT.\n\npackage store\n",
+	})
+	run("OtherGenerator", testCase{
+		contents: "// Code generated by stringer; DO NOT EDIT.\n\npackage store\n",
+	})
+	run("HeaderAfterFirstLine", testCase{
+		contents: "package store\n\n// Code generated by mock from store.go. DO NOT EDIT.\n",
+	})
+	run("Empty", testCase{
+		contents: "",
+	})
+}
+
+func TestOrphanedFiles(t *testing.T) {
+	// Package store's directives produce store_mock.go, and used to produce
+	// stale_mock.go, excluded_mock.go, and mocks/remote_mock.go, which belongs
+	// to package mocks.
+	dir := t.TempDir()
+	files := map[string]string{
+		"store.go":              "package store\n\n//go:mock\ntype Store interface{}\n\ntype Getter interface{}\n",
+		"store_mock.go":         "// Code generated by mock from store.go. DO NOT EDIT.\n\npackage store\n",
+		"stale_mock.go":         "// Code generated by mock from store.go. DO NOT EDIT.\n\npackage store\n",
+		"excluded_mock.go":      "// Code generated by mock from store.go. DO NOT EDIT.\n\n//go:build never\n\npackage store\n",
+		"getter_mock.go":        "// Code generated by mock for Getter from store.go. DO NOT EDIT.\n\npackage store\n",
+		"store_test.go":         "package store\n",
+		"mocks/mocks.go":        "package mocks\n",
+		"mocks/remote_mock.go":  "// Code generated by mock from ../store.go. DO NOT EDIT.\n\npackage mocks\n",
+		"mocks/unknown_mock.go": "// Code generated by mock from ../gone/gone.go. DO NOT EDIT.\n\npackage mocks\n",
+	}
+	paths := map[string]string{}
+	for name, contents := range files {
+		paths[name] = filepath.Join(dir, filepath.FromSlash(name))
+		if mkdirErr := os.MkdirAll(filepath.Dir(paths[name]), 0o755); mkdirErr != nil {
+			t.Fatal(mkdirErr)
+		}
+		if writeErr := os.WriteFile(paths[name], []byte(contents), 0o644); writeErr != nil {
+			t.Fatal(writeErr)
+		}
+	}
+	var (
+		storePkg = &packages.Package{
+			ID:           "example.com/store",
+			GoFiles:      []string{paths["store.go"], paths["store_mock.go"], paths["stale_mock.go"], paths["getter_mock.go"]},
+			IgnoredFiles: []string{paths["excluded_mock.go"]},
+		}
+		storeTestPkg = &packages.Package{
+			ID:      "example.com/store [example.com/store.test]",
+			GoFiles: append(slices.Clone(storePkg.GoFiles), paths["store_test.go"]),
+		}
+		mocksPkg = &packages.Package{
+			ID:      "example.com/store/mocks",
+			GoFiles: []string{paths["mocks/mocks.go"], paths["mocks/remote_mock.go"], paths["mocks/unknown_mock.go"]},
+		}
+	)
+
+	type testCase struct {
+		pkgs     []*packages.Package
+		expected []string
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			actual, err := orphanedFiles(testCase.pkgs, map[string]iface.File{
+				paths["store_mock.go"]: {},
+			})
+			expect.ErrorNil(t, err)
+			expect.Equal(t, actual, testCase.expected)
+		})
+	}
+
+	// Mocks whose source files' packages weren't searched are kept, since
+	// their directives may still produce them.
+	run("AllPackages", testCase{
+		pkgs:     []*packages.Package{storePkg, storeTestPkg, mocksPkg},
+		expected: []string{paths["excluded_mock.go"], paths["mocks/remote_mock.go"], paths["stale_mock.go"]},
+	})
+	run("SourcePackageOnly", testCase{
+		pkgs:     []*packages.Package{storePkg, storeTestPkg},
+		expected: []string{paths["excluded_mock.go"], paths["stale_mock.go"]},
+	})
+	run("OutputPackageOnly", testCase{
+		pkgs: []*packages.Package{mocksPkg},
+	})
+}
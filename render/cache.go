@@ -0,0 +1,64 @@
+package render
+
+import (
+	"sync"
+
+	"github.com/nicheinc/mock/iface"
+)
+
+// templateKey identifies a mock template by its style and the path of the
+// custom template layered over it, if any.
+type templateKey struct {
+	style string
+	path  string
+}
+
+// Cache loads each mock template once, since many mock files typically share a
+// template. It's safe for concurrent use.
+type Cache struct {
+	mu        sync.Mutex
+	templates map[templateKey]*Template
+}
+
+// NewCache returns an empty template cache.
+func NewCache() *Cache {
+	return &Cache{templates: map[templateKey]*Template{}}
+}
+
+// Load returns the template at the given path layered over the given style's
+// template, or the style's template itself if the path is empty.
+func (c *Cache) Load(style, path string) (*Template, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := templateKey{style: style, path: path}
+	if tmpl, loaded := c.templates[key]; loaded {
+		return tmpl, nil
+	}
+	tmpl, loadErr := Load(style, path)
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	c.templates[key] = tmpl
+	return tmpl, nil
+}
+
+// Imports returns the import paths required by the given style and template.
+// It has the signature of iface.Options' TemplateImports.
+func (c *Cache) Imports(style, path string) ([]string, error) {
+	tmpl, loadErr := c.Load(style, path)
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	return tmpl.Imports(), nil
+}
+
+// Declarations returns the identifiers that the given file's template declares
+// at package scope for the mock of the given interface. It has the signature
+// expected by iface.CheckMockNames.
+func (c *Cache) Declarations(file iface.File, ifaceInfo iface.Interface) ([]string, error) {
+	tmpl, loadErr := c.Load(file.Style, file.Template)
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	return tmpl.Declarations(ifaceInfo)
+}
@@ -0,0 +1,85 @@
+package render
+
+import (
+	"errors"
+	"go/version"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Funcs are the helper functions available to templates, in addition to the
+// text/template package's predefined functions.
+var Funcs = template.FuncMap{
+	// join concatenates strings with the given separator.
+	"join": strings.Join,
+	// quote returns a double-quoted Go string literal.
+	"quote": strconv.Quote,
+	// lower and upper change the case of strings.
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	// lowerFirst and upperFirst change the case of the first letter of a
+	// string, e.g. to derive unexported names from exported ones.
+	"lowerFirst": lowerFirst,
+	"upperFirst": upperFirst,
+	// trimPrefix and trimSuffix remove a prefix or suffix from a string, given
+	// first.
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	// replace replaces all occurrences of old in a string, given last, with new.
+	"replace": func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	// add adds integers, e.g. to number parameters from one.
+	"add": func(a, b int) int { return a + b },
+	// dict builds a map from alternating keys and values, e.g. to pass several
+	// values to a template.
+	"dict": dict,
+	// goAtLeast reports whether the mock file's Go language version, if known,
+	// is at least the given one, e.g. "go1.21", so that templates can use newer
+	// language features and APIs only where they're available. It's true if the
+	// file's version is unknown.
+	"goAtLeast": func(string) bool { return true },
+}
+
+// lowerFirst lowercases the first letter of the string.
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[size:]
+}
+
+// upperFirst uppercases the first letter of the string.
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// dict builds a map from alternating string keys and values.
+func dict(pairs ...any) (map[string]any, error) {
+	if len(pairs)%2 != 0 {
+		return nil, errors.New("dict requires an even number of arguments")
+	}
+	m := make(map[string]any, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, isString := pairs[i].(string)
+		if !isString {
+			return nil, errors.New("dict keys must be strings")
+		}
+		m[key] = pairs[i+1]
+	}
+	return m, nil
+}
+
+// goAtLeast returns the goAtLeast function for a mock file with the given Go
+// language version.
+func goAtLeast(fileVersion string) func(string) bool {
+	return func(minVersion string) bool {
+		return fileVersion == "" || version.Compare(fileVersion, minVersion) >= 0
+	}
+}
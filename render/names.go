@@ -0,0 +1,131 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/nicheinc/mock/iface"
+)
+
+// renameParams returns a copy of the given mock file whose methods' parameters
+// and named results are renamed where they would collide with the identifiers
+// that the template's stubs declare or use, which are listed for each method by
+// the template's "reserved" template, or with the packages the file imports,
+// since a parameter named after a package would shadow it in the types that
+// stubs spell out. The file's own slices aren't modified.
+func (t *Template) renameParams(file iface.File) (iface.File, error) {
+	var importNames []string
+	for _, importPath := range t.imports {
+		importNames = append(importNames, path.Base(importPath))
+	}
+	for _, imp := range file.Imports {
+		if name := imp.LocalName(); name != "" && name != "_" && name != "." {
+			importNames = append(importNames, name)
+		}
+	}
+	reservedTmpl := t.tmpl.Lookup("reserved")
+
+	file.Interfaces = slices.Clone(file.Interfaces)
+	for i := range file.Interfaces {
+		ifaceInfo := &file.Interfaces[i]
+		ifaceInfo.Methods = slices.Clone(ifaceInfo.Methods)
+		for j := range ifaceInfo.Methods {
+			method := &ifaceInfo.Methods[j]
+			buf := &bytes.Buffer{}
+			if reservedTmpl != nil {
+				data := map[string]any{"Interface": *ifaceInfo, "Method": *method}
+				if executeErr := reservedTmpl.Execute(buf, data); executeErr != nil {
+					return iface.File{}, fmt.Errorf("listing reserved names: %w", executeErr)
+				}
+			}
+			reserved := map[string]bool{}
+			for _, name := range slices.Concat(strings.Fields(buf.String()), importNames) {
+				reserved[name] = true
+			}
+			method.Params, method.Results = renameMethodParams(method.Params, method.Results, reserved)
+		}
+	}
+	return file, nil
+}
+
+// renameMethodParams renames the given parameters and named results that are
+// reserved, or that would share the name given to another, unnamed parameter.
+// Each takes the name that Params.Names gives unnamed parameters, e.g. param1,
+// or result1 for results, followed by underscores until it's unique. Unnamed
+// parameters and results are left unnamed, since Go doesn't allow them to be
+// mixed with named ones.
+func renameMethodParams(params iface.Params, results iface.Results, reserved map[string]bool) (iface.Params, iface.Results) {
+	var (
+		paramNames = params.Names()
+		taken      = map[string]bool{}
+		renamed    = map[int]bool{}
+		collides   = func(name string) bool { return reserved[name] || taken[name] }
+	)
+	for i, name := range paramNames {
+		if reserved[name] || taken[name] {
+			renamed[i] = true
+			continue
+		}
+		taken[name] = true
+	}
+	for _, result := range results {
+		if result.Name != "" && result.Name != "_" && !reserved[result.Name] {
+			taken[result.Name] = true
+		}
+	}
+	if len(renamed) == 0 && !slices.ContainsFunc(results, func(result iface.Result) bool { return reserved[result.Name] }) {
+		return params, results
+	}
+
+	params = slices.Clone(params)
+	for i := range params {
+		if !renamed[i] {
+			continue
+		}
+		name := fmt.Sprintf("param%d", i+1)
+		for collides(name) {
+			name += "_"
+		}
+		params[i].Name = name
+		taken[name] = true
+	}
+	results = slices.Clone(results)
+	for i := range results {
+		if !reserved[results[i].Name] {
+			continue
+		}
+		name := fmt.Sprintf("result%d", i+1)
+		for collides(name) {
+			name += "_"
+		}
+		results[i].Name = name
+		taken[name] = true
+	}
+	return params, results
+}
+
+// checkMembers returns an error if any of the fields and methods that the
+// template declares on a mock, besides the interface's methods, shares its
+// name with one of the interface's methods. They're listed for each interface
+// by the template's "members" template.
+func (t *Template) checkMembers(outputPath string, file iface.File) error {
+	membersTmpl := t.tmpl.Lookup("members")
+	if membersTmpl == nil {
+		return nil
+	}
+	for _, ifaceInfo := range file.Interfaces {
+		buf := &bytes.Buffer{}
+		if executeErr := membersTmpl.Execute(buf, ifaceInfo); executeErr != nil {
+			return fmt.Errorf("listing members: %w", executeErr)
+		}
+		for _, name := range strings.Fields(buf.String()) {
+			if slices.ContainsFunc(ifaceInfo.Methods, func(method iface.Method) bool { return method.Name == name }) {
+				return fmt.Errorf("%s, declared by mock %s of %s in %s, collides with method %s.%s; choose another style with the -style option", name, ifaceInfo.MockName, ifaceInfo.Name, outputPath, ifaceInfo.Name, name)
+			}
+		}
+	}
+	return nil
+}
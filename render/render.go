@@ -0,0 +1,174 @@
+// Package render renders mock files from their text-template-friendly
+// representations, using the template of a built-in style or a custom template
+// layered over it.
+package render
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/nicheinc/mock/iface"
+	"golang.org/x/tools/imports"
+)
+
+// DefaultStyle is the style used when none is given, whose mocks have a stub
+// and a call counter for each method.
+const DefaultStyle = "minimal"
+
+// Styles are the names of the built-in templates.
+var Styles = []string{"minimal", "recorder", "fluent", "moq", "gomock"}
+
+//go:embed base.tmpl styles/*.tmpl
+var templates embed.FS
+
+// Template is a parsed mock template, which is executed with an iface.File.
+type Template struct {
+	tmpl    *template.Template
+	imports []string
+}
+
+// parseStyle parses the built-in template for the given style, or the default
+// style if it's empty.
+func parseStyle(style string) (*template.Template, error) {
+	if style == "" {
+		style = DefaultStyle
+	}
+	if !slices.Contains(Styles, style) {
+		return nil, fmt.Errorf("unknown style %q (expected one of %s)", style, strings.Join(Styles, ", "))
+	}
+	tmpl := template.New("file").Funcs(Funcs)
+	for _, name := range []string{"base.tmpl", "styles/" + style + ".tmpl"} {
+		contents, readErr := templates.ReadFile(name)
+		if readErr != nil {
+			return nil, readErr
+		}
+		if _, parseErr := tmpl.Parse(string(contents)); parseErr != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, parseErr)
+		}
+	}
+	return tmpl, nil
+}
+
+// Default returns the default style's template.
+func Default() (*Template, error) {
+	return Load("", "")
+}
+
+// Load returns the custom template in the given file, layered over the given
+// style's template, or the style's template itself if the path is empty. A
+// custom template may override the templates defined by the style's template,
+// and replaces it altogether if it includes any text outside of definitions.
+func Load(style, path string) (*Template, error) {
+	base, parseErr := parseStyle(style)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	if path == "" {
+		return newTemplate(base)
+	}
+	contents, readErr := os.ReadFile(path)
+	if readErr != nil {
+		return nil, readErr
+	}
+	custom, parseErr := template.New("file").Funcs(Funcs).Parse(string(contents))
+	if parseErr != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, parseErr)
+	}
+
+	// Combine the style's and custom templates, preferring the custom ones.
+	// Parsing the custom template into the style's one wouldn't do, since
+	// definitions that are empty, e.g. of templates requiring no imports,
+	// don't replace existing ones.
+	tmpl := template.New("file").Funcs(Funcs)
+	for _, t := range base.Templates() {
+		if overridden(custom.Lookup(t.Name())) {
+			continue
+		}
+		if _, addErr := tmpl.AddParseTree(t.Name(), t.Tree); addErr != nil {
+			return nil, addErr
+		}
+	}
+	for _, t := range custom.Templates() {
+		if !overridden(t) {
+			continue
+		}
+		if _, addErr := tmpl.AddParseTree(t.Name(), t.Tree); addErr != nil {
+			return nil, addErr
+		}
+	}
+	return newTemplate(tmpl)
+}
+
+// overridden reports whether the given custom template, which may be nil,
+// overrides the style's template of the same name. Any definition overrides
+// the style's, but the custom file's own text only does if it's nonempty.
+func overridden(t *template.Template) bool {
+	if t == nil || t.Tree == nil {
+		return false
+	}
+	return t.Name() != "file" || !parse.IsEmptyTree(t.Tree.Root)
+}
+
+// newTemplate determines the imports required by the given template, which
+// are listed by its "imports" template.
+func newTemplate(tmpl *template.Template) (*Template, error) {
+	buf := &bytes.Buffer{}
+	if executeErr := tmpl.ExecuteTemplate(buf, "imports", nil); executeErr != nil {
+		return nil, fmt.Errorf("listing imports: %w", executeErr)
+	}
+	return &Template{tmpl: tmpl, imports: strings.Fields(buf.String())}, nil
+}
+
+// Declarations returns the identifiers that the template declares at package
+// scope for the mock of the given interface, which are listed by its
+// "declarations" template.
+func (t *Template) Declarations(ifaceInfo iface.Interface) ([]string, error) {
+	buf := &bytes.Buffer{}
+	if executeErr := t.tmpl.ExecuteTemplate(buf, "declarations", ifaceInfo); executeErr != nil {
+		return nil, fmt.Errorf("listing declarations: %w", executeErr)
+	}
+	return strings.Fields(buf.String()), nil
+}
+
+// Imports returns the import paths that the template requires, whose package
+// names mustn't be taken by the interfaces' imports.
+func (t *Template) Imports() []string {
+	return t.imports
+}
+
+// Render executes the template for the given mock file and formats the result.
+func (t *Template) Render(outputPath string, file iface.File) ([]byte, error) {
+	file, renameErr := t.renameParams(file)
+	if renameErr != nil {
+		return nil, fmt.Errorf("executing template for %s: %w", outputPath, renameErr)
+	}
+	if checkErr := t.checkMembers(outputPath, file); checkErr != nil {
+		return nil, checkErr
+	}
+
+	// Templates that depend on the file's Go version are executed with a
+	// clone, since the template is shared.
+	tmpl := t.tmpl
+	if file.GoVersion != "" {
+		clone, cloneErr := tmpl.Clone()
+		if cloneErr != nil {
+			return nil, cloneErr
+		}
+		tmpl = clone.Funcs(template.FuncMap{"goAtLeast": goAtLeast(file.GoVersion)})
+	}
+	buf := &bytes.Buffer{}
+	if executeErr := tmpl.Execute(buf, file); executeErr != nil {
+		return nil, fmt.Errorf("executing template for %s: %w", outputPath, executeErr)
+	}
+	formatted, importsErr := imports.Process(outputPath, buf.Bytes(), nil)
+	if importsErr != nil {
+		return nil, fmt.Errorf("formatting %s: %w", outputPath, importsErr)
+	}
+	return formatted, nil
+}
@@ -0,0 +1,675 @@
+package render
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nicheinc/expect"
+	"github.com/nicheinc/mock/iface"
+)
+
+func TestLoad(t *testing.T) {
+	file := iface.File{
+		Package:     "store",
+		PackagePath: "example.com/store",
+		SourceFiles: []string{"store.go"},
+		Interfaces: []iface.Interface{{
+			Name:     "Store",
+			MockName: "StoreMock",
+			Methods: iface.Methods{{
+				Name:    "Get",
+				Params:  iface.Params{{Name: "key", Type: "string"}},
+				Results: iface.Results{{Type: "int"}},
+			}},
+		}},
+	}
+	type testCase struct {
+		template        string
+		expectedImports []string
+		expectedOutput  []string
+		absentOutput    []string
+		errorExpected   bool
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			path := filepath.Join(t.TempDir(), "mock.tmpl")
+			if writeErr := os.WriteFile(path, []byte(testCase.template), 0o666); writeErr != nil {
+				t.Fatal(writeErr)
+			}
+			tmpl, loadErr := Load("", path)
+			if testCase.errorExpected {
+				expect.ErrorNonNil(t, loadErr)
+				return
+			}
+			expect.ErrorNil(t, loadErr)
+			expect.Equal(t, tmpl.Imports(), testCase.expectedImports)
+
+			rendered, renderErr := tmpl.Render("store_mock.go", file)
+			expect.ErrorNil(t, renderErr)
+			for _, expected := range testCase.expectedOutput {
+				if !strings.Contains(string(rendered), expected) {
+					t.Errorf("expected output to contain %q:\n%s", expected, rendered)
+				}
+			}
+			for _, absent := range testCase.absentOutput {
+				if strings.Contains(string(rendered), absent) {
+					t.Errorf("expected output not to contain %q:\n%s", absent, rendered)
+				}
+			}
+		})
+	}
+
+	run("OverrideBlock", testCase{
+		template: `{{ define "method" }}{{ with .Method }}func (m *{{ $.Interface.MockName }}) {{ .Name }}({{ .Params.NamedString }}) {{ .Results }} {
+	return {{ .Results.ZeroString }}
+}{{ end }}{{ end }}`,
+		expectedImports: []string{"sync/atomic", "testing"},
+		expectedOutput: []string{
+			"// Code generated by mock from store.go. DO NOT EDIT.",
+			"GetCalled int32",
+			"func (m *StoreMock) Get(key string) int {\n\treturn *new(int)\n}",
+		},
+		absentOutput: []string{"atomic.AddInt32"},
+	})
+	run("OverrideImports", testCase{
+		template:        `{{ define "imports" }}sync testing{{ end }}`,
+		expectedImports: []string{"sync", "testing"},
+		expectedOutput:  []string{"GetCalled int32"},
+	})
+	run("ReplaceFile", testCase{
+		template: `{{ template "header" . }}
+{{ range .Interfaces }}
+type {{ lowerFirst .MockName }} struct{}
+{{ end }}
+{{- define "imports" }}{{ end }}`,
+		expectedImports: []string{},
+		expectedOutput:  []string{"package store", "type storeMock struct{}"},
+		absentOutput:    []string{"GetStub"},
+	})
+	run("ParseError", testCase{
+		template:      `{{ define "method" }}`,
+		errorExpected: true,
+	})
+}
+
+func TestStyles(t *testing.T) {
+	file := iface.File{
+		Package:     "store",
+		PackagePath: "example.com/store",
+		SourceFiles: []string{"store.go"},
+		Interfaces: []iface.Interface{{
+			Name:       "Store",
+			MockName:   "StoreMock",
+			TypeParams: iface.TypeParams{{Name: "V", Constraint: "any"}},
+			Methods: iface.Methods{
+				{
+					Name:    "Get",
+					Params:  iface.Params{{Name: "key", Type: "string"}},
+					Results: iface.Results{{Type: "V"}, {Type: "error"}},
+				},
+				{
+					Name:   "Put",
+					Params: iface.Params{{Type: "string"}, {Type: "[]V", Variadic: true}},
+				},
+				{
+					Name: "Close",
+				},
+			},
+		}},
+	}
+	type testCase struct {
+		style          string
+		expectedOutput []string
+		errorExpected  bool
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			tmpl, loadErr := Load(testCase.style, "")
+			if testCase.errorExpected {
+				expect.ErrorNonNil(t, loadErr)
+				return
+			}
+			expect.ErrorNil(t, loadErr)
+
+			rendered, renderErr := tmpl.Render("store_mock.go", file)
+			expect.ErrorNil(t, renderErr)
+			for _, expected := range testCase.expectedOutput {
+				if !strings.Contains(string(rendered), expected) {
+					t.Errorf("expected output to contain %q:\n%s", expected, rendered)
+				}
+			}
+		})
+	}
+
+	run("Default", testCase{
+		style: "",
+		expectedOutput: []string{
+			"GetStub     func(key string) (V, error)",
+			"atomic.AddInt32(&m.PutCalled, 1)",
+		},
+	})
+	run("Minimal", testCase{
+		style:          "minimal",
+		expectedOutput: []string{"GetStub     func(key string) (V, error)"},
+	})
+	run("Recorder", testCase{
+		style: "recorder",
+		expectedOutput: []string{
+			"type StoreMockPutCall[V any] struct {\n\tParam1 string\n\tParam2 []V\n}",
+			"type StoreMockCloseCall[V any] struct{}",
+			"func (m *StoreMock[V]) PutCalls() []StoreMockPutCall[V] {",
+		},
+	})
+	run("Fluent", testCase{
+		style: "fluent",
+		expectedOutput: []string{
+			"func NewStoreMock[V any](t *testing.T) *StoreMock[V] {",
+			"func (m *StoreMock[V]) ExpectPut(param1 string, param2 ...V) *StoreMockPutExpectation[V] {",
+			"func (e *StoreMockGetExpectation[V]) Return(result1 V, result2 error) *StoreMockGetExpectation[V] {",
+			"if e.calls < e.times && reflect.DeepEqual(e.argParam1, param1) && reflect.DeepEqual(e.argParam2, param2) {",
+			"func (m *StoreMock[V]) AssertExpectations() {",
+		},
+	})
+	run("Moq", testCase{
+		style: "moq",
+		expectedOutput: []string{
+			"GetFunc func(key string) (V, error)",
+			"lockPut   sync.RWMutex",
+			"func (mock *StoreMock[V]) PutCalls() []struct {\n\tParam1 string\n\tParam2 []V\n} {",
+			`panic("StoreMock.CloseFunc: method is nil but Store.Close was just called")`,
+		},
+	})
+	run("Gomock", testCase{
+		style: "gomock",
+		expectedOutput: []string{
+			`"github.com/nicheinc/mock/gomock"`,
+			"func NewStoreMock[V any](ctrl *gomock.Controller) *StoreMock[V] {",
+			"ret := m.ctrl.Call(m, \"Get\", key)\n\tret0, _ := ret[0].(V)\n\tret1, _ := ret[1].(error)\n\treturn ret0, ret1",
+			"varargs := []any{param1}\n\tfor _, a := range param2 {",
+			"func (mr *StoreMockMockRecorder[V]) Put(param1 any, param2 ...any) *gomock.Call {",
+			"reflect.TypeOf((*StoreMock[V])(nil).Close))",
+		},
+	})
+	run("Unknown", testCase{
+		style:         "verbose",
+		errorExpected: true,
+	})
+}
+
+func TestGoVersion(t *testing.T) {
+	const source = "package store\n\ntype Store interface {\n\tPut(string, ...int)\n}\n"
+	var (
+		fset        = token.NewFileSet()
+		srcImporter = importer.ForCompiler(fset, "source", nil)
+	)
+	file := iface.File{
+		Package:     "store",
+		PackagePath: "example.com/store",
+		SourceFiles: []string{"store.go"},
+		Imports: []iface.Import{
+			{Path: "slices", Package: "slices"},
+			{Path: "sync", Package: "sync"},
+			{Path: "sync/atomic", Package: "atomic"},
+			{Path: "testing", Package: "testing"},
+		},
+		Interfaces: []iface.Interface{{
+			Name:     "Store",
+			MockName: "StoreMock",
+			Methods: iface.Methods{{
+				Name:   "Put",
+				Params: iface.Params{{Type: "string"}, {Type: "[]int", Variadic: true}},
+			}},
+		}},
+	}
+	type testCase struct {
+		goVersion        string
+		expectedOutput   []string
+		unexpectedOutput []string
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			tmpl, loadErr := Load("recorder", "")
+			expect.ErrorNil(t, loadErr)
+
+			file := file
+			file.GoVersion = testCase.goVersion
+			rendered, renderErr := tmpl.Render("store_mock.go", file)
+			expect.ErrorNil(t, renderErr)
+			if checkErr := typeCheck(t, fset, srcImporter, source, rendered, testCase.goVersion); checkErr != nil {
+				t.Errorf("%v:\n%s", checkErr, rendered)
+			}
+			for _, expected := range testCase.expectedOutput {
+				if !strings.Contains(string(rendered), expected) {
+					t.Errorf("expected output to contain %q:\n%s", expected, rendered)
+				}
+			}
+			for _, unexpected := range testCase.unexpectedOutput {
+				if strings.Contains(string(rendered), unexpected) {
+					t.Errorf("expected output not to contain %q:\n%s", unexpected, rendered)
+				}
+			}
+		})
+	}
+
+	run("Unknown", testCase{
+		expectedOutput: []string{
+			"\"slices\"",
+			"return slices.Clone(m.putCalls)",
+		},
+	})
+	run("Clone", testCase{
+		goVersion: "go1.21",
+		expectedOutput: []string{
+			"\"slices\"",
+			"return slices.Clone(m.putCalls)",
+		},
+	})
+	run("Append", testCase{
+		goVersion: "go1.20",
+		expectedOutput: []string{
+			"return append([]StoreMockPutCall(nil), m.putCalls...)",
+		},
+		unexpectedOutput: []string{
+			"\"slices\"",
+		},
+	})
+}
+
+func TestDefault(t *testing.T) {
+	tmpl, loadErr := Default()
+	expect.ErrorNil(t, loadErr)
+	expect.Equal(t, tmpl.Imports(), []string{"sync/atomic", "testing"})
+}
+
+func TestFuncs(t *testing.T) {
+	type testCase struct {
+		actual   string
+		expected string
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			expect.Equal(t, testCase.actual, testCase.expected)
+		})
+	}
+
+	run("LowerFirst", testCase{
+		actual:   lowerFirst("StoreMock"),
+		expected: "storeMock",
+	})
+	run("LowerFirst/Empty", testCase{
+		actual:   lowerFirst(""),
+		expected: "",
+	})
+	run("UpperFirst", testCase{
+		actual:   upperFirst("ötzi"),
+		expected: "Ötzi",
+	})
+	run("UpperFirst/Empty", testCase{
+		actual:   upperFirst(""),
+		expected: "",
+	})
+}
+
+func TestRenameMethodParams(t *testing.T) {
+	reserved := map[string]bool{"m": true, "e": true, "ret": true}
+	type testCase struct {
+		params          iface.Params
+		results         iface.Results
+		expectedParams  iface.Params
+		expectedResults iface.Results
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			params, results := renameMethodParams(testCase.params, testCase.results, reserved)
+			expect.Equal(t, params, testCase.expectedParams)
+			expect.Equal(t, results, testCase.expectedResults)
+		})
+	}
+
+	run("NoCollisions", testCase{
+		params:          iface.Params{{Name: "key", Type: "string"}},
+		results:         iface.Results{{Type: "int"}},
+		expectedParams:  iface.Params{{Name: "key", Type: "string"}},
+		expectedResults: iface.Results{{Type: "int"}},
+	})
+	run("Unnamed", testCase{
+		params:          iface.Params{{Type: "string"}, {Type: "int"}},
+		expectedParams:  iface.Params{{Type: "string"}, {Type: "int"}},
+		expectedResults: nil,
+	})
+	run("Reserved", testCase{
+		params:          iface.Params{{Name: "key", Type: "string"}, {Name: "e", Type: "[]int", Variadic: true}},
+		results:         iface.Results{{Name: "ret", Type: "int"}, {Name: "err", Type: "error"}},
+		expectedParams:  iface.Params{{Name: "key", Type: "string"}, {Name: "param2", Type: "[]int", Variadic: true}},
+		expectedResults: iface.Results{{Name: "result1", Type: "int"}, {Name: "err", Type: "error"}},
+	})
+	run("RenamedNameTaken", testCase{
+		params:          iface.Params{{Name: "m", Type: "string"}, {Name: "param1", Type: "int"}},
+		results:         iface.Results{{Name: "param1_", Type: "int"}},
+		expectedParams:  iface.Params{{Name: "param1__", Type: "string"}, {Name: "param1", Type: "int"}},
+		expectedResults: iface.Results{{Name: "param1_", Type: "int"}},
+	})
+	run("BlankParam", testCase{
+		params:          iface.Params{{Name: "_", Type: "string"}, {Name: "param1", Type: "int"}},
+		expectedParams:  iface.Params{{Name: "_", Type: "string"}, {Name: "param2", Type: "int"}},
+		expectedResults: nil,
+	})
+}
+
+// typeCheck parses and type-checks the given rendered mock file along with the
+// source declaring its interfaces, in the given Go version.
+func typeCheck(t *testing.T, fset *token.FileSet, importer types.Importer, source string, rendered []byte, goVersion string) error {
+	t.Helper()
+	sourceFile, parseErr := parser.ParseFile(fset, "p.go", source, 0)
+	if parseErr != nil {
+		t.Fatal(parseErr)
+	}
+	mockFile, parseErr := parser.ParseFile(fset, "p_mock.go", rendered, 0)
+	if parseErr != nil {
+		return parseErr
+	}
+	config := types.Config{Importer: importer, GoVersion: goVersion}
+	_, checkErr := config.Check("example.com/p", fset, []*ast.File{sourceFile, mockFile}, nil)
+	return checkErr
+}
+
+func TestStylesCompile(t *testing.T) {
+	// Share the importer, which type-checks imported packages from source, so
+	// that it only does so once.
+	var (
+		fset        = token.NewFileSet()
+		srcImporter = importer.ForCompiler(fset, "source", nil)
+	)
+	type testCase struct {
+		// Source of package p, declaring the interface
+		source    string
+		ifaceInfo iface.Interface
+		goVersion string
+		// Imports of the interface's method signatures
+		imports []iface.Import
+		// Styles to compile, or all of them if empty
+		styles []string
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		styles := testCase.styles
+		if len(styles) == 0 {
+			styles = Styles
+		}
+		for _, style := range styles {
+			t.Run(name+"/"+style, func(t *testing.T) {
+				t.Helper()
+				tmpl, loadErr := Load(style, "")
+				expect.ErrorNil(t, loadErr)
+				file := iface.File{
+					Package:     "p",
+					PackagePath: "example.com/p",
+					SourceFiles: []string{"p.go"},
+					Interfaces:  []iface.Interface{testCase.ifaceInfo},
+					GoVersion:   testCase.goVersion,
+				}
+				for _, importPath := range tmpl.Imports() {
+					file.Imports = append(file.Imports, iface.Import{Path: importPath, Package: path.Base(importPath)})
+				}
+				file.Imports = append(file.Imports, testCase.imports...)
+				rendered, renderErr := tmpl.Render("p_mock.go", file)
+				expect.ErrorNil(t, renderErr)
+				if checkErr := typeCheck(t, fset, srcImporter, testCase.source, rendered, testCase.goVersion); checkErr != nil {
+					t.Errorf("%v:\n%s", checkErr, rendered)
+				}
+			})
+		}
+	}
+
+	run("Basic", testCase{
+		source: "package p\n\ntype Store interface {\n\tGet(key string) (int, error)\n\tClose()\n}\n",
+		ifaceInfo: iface.Interface{
+			Name:     "Store",
+			MockName: "StoreMock",
+			Methods: iface.Methods{
+				{Name: "Get", Params: iface.Params{{Name: "key", Type: "string"}}, Results: iface.Results{{Type: "int"}, {Type: "error"}}},
+				{Name: "Close"},
+			},
+		},
+	})
+	run("Lenient", testCase{
+		source: "package p\n\ntype Store interface {\n\tGet(key string) (int, error)\n}\n",
+		ifaceInfo: iface.Interface{
+			Name:     "Store",
+			MockName: "StoreMock",
+			Lenient:  true,
+			Methods: iface.Methods{
+				{Name: "Get", Params: iface.Params{{Name: "key", Type: "string"}}, Results: iface.Results{{Type: "int"}, {Type: "error"}}},
+			},
+		},
+	})
+	run("Generic", testCase{
+		source: "package p\n\ntype Store[K comparable, V any] interface {\n\tGet(key K) (V, error)\n\tPut(key K, values ...V)\n}\n",
+		ifaceInfo: iface.Interface{
+			Name:       "Store",
+			MockName:   "StoreMock",
+			TypeParams: iface.TypeParams{{Name: "K", Constraint: "comparable"}, {Name: "V", Constraint: "any"}},
+			Methods: iface.Methods{
+				{Name: "Get", Params: iface.Params{{Name: "key", Type: "K"}}, Results: iface.Results{{Type: "V"}, {Type: "error"}}},
+				{Name: "Put", Params: iface.Params{{Name: "key", Type: "K"}, {Name: "values", Type: "[]V", Variadic: true}}},
+			},
+		},
+	})
+	run("Variadic", testCase{
+		source: "package p\n\ntype Logger interface {\n\tLogf(format string, args ...any)\n\tLog(args ...any) int\n}\n",
+		ifaceInfo: iface.Interface{
+			Name:     "Logger",
+			MockName: "LoggerMock",
+			Methods: iface.Methods{
+				{Name: "Logf", Params: iface.Params{{Name: "format", Type: "string"}, {Name: "args", Type: "[]any", Variadic: true}}},
+				{Name: "Log", Params: iface.Params{{Name: "args", Type: "[]any", Variadic: true}}, Results: iface.Results{{Type: "int"}}},
+			},
+		},
+	})
+	run("Unnamed", testCase{
+		source: "package p\n\ntype Store interface {\n\tPut(string, int, ...bool) error\n\tSet(_ string, param1 int)\n}\n",
+		ifaceInfo: iface.Interface{
+			Name:     "Store",
+			MockName: "StoreMock",
+			Methods: iface.Methods{
+				{Name: "Put", Params: iface.Params{{Type: "string"}, {Type: "int"}, {Type: "[]bool", Variadic: true}}, Results: iface.Results{{Type: "error"}}},
+				{Name: "Set", Params: iface.Params{{Name: "_", Type: "string"}, {Name: "param1", Type: "int"}}},
+			},
+		},
+	})
+	run("Collisions", testCase{
+		source: "package p\n\ntype Store interface {\n\tDo(m, mr, e, ret, varargs, mock, callInfo, calls, reflect, sync string, a ...int) (ret0 int, result1 error)\n}\n",
+		ifaceInfo: iface.Interface{
+			Name:     "Store",
+			MockName: "StoreMock",
+			Methods: iface.Methods{{
+				Name: "Do",
+				Params: iface.Params{
+					{Name: "m", Type: "string"}, {Name: "mr", Type: "string"}, {Name: "e", Type: "string"},
+					{Name: "ret", Type: "string"}, {Name: "varargs", Type: "string"}, {Name: "mock", Type: "string"},
+					{Name: "callInfo", Type: "string"}, {Name: "calls", Type: "string"}, {Name: "reflect", Type: "string"},
+					{Name: "sync", Type: "string"}, {Name: "a", Type: "[]int", Variadic: true},
+				},
+				Results: iface.Results{{Name: "ret0", Type: "int"}, {Name: "result1", Type: "error"}},
+			}},
+		},
+	})
+	run("ShadowedImport", testCase{
+		source: "package p\n\nimport \"net/url\"\n\ntype Parser interface {\n\tParse(url *url.URL) error\n}\n",
+		ifaceInfo: iface.Interface{
+			Name:     "Parser",
+			MockName: "ParserMock",
+			Methods: iface.Methods{
+				{Name: "Parse", Params: iface.Params{{Name: "url", Type: "*url.URL"}}, Results: iface.Results{{Type: "error"}}},
+			},
+		},
+		imports: []iface.Import{{Path: "net/url", Package: "url"}},
+	})
+	run("ShadowedResultImport", testCase{
+		source: "package p\n\nimport \"net/url\"\n\ntype Getter interface {\n\tGet(url string) (*url.URL, error)\n}\n",
+		ifaceInfo: iface.Interface{
+			Name:     "Getter",
+			MockName: "GetterMock",
+			Methods: iface.Methods{
+				{Name: "Get", Params: iface.Params{{Name: "url", Type: "string"}}, Results: iface.Results{{Type: "*url.URL"}, {Type: "error"}}},
+			},
+		},
+		imports: []iface.Import{{Path: "net/url", Package: "url"}},
+	})
+	run("LenientShadowedImport", testCase{
+		source: "package p\n\nimport \"net/url\"\n\ntype Getter interface {\n\tGet(url string) (url.URL, error)\n}\n",
+		ifaceInfo: iface.Interface{
+			Name:     "Getter",
+			MockName: "GetterMock",
+			Lenient:  true,
+			Methods: iface.Methods{
+				{Name: "Get", Params: iface.Params{{Name: "url", Type: "string"}}, Results: iface.Results{{Type: "url.URL"}, {Type: "error"}}},
+			},
+		},
+		imports: []iface.Import{{Path: "net/url", Package: "url"}},
+	})
+	run("OldGoVersion", testCase{
+		source: "package p\n\ntype Logger interface {\n\tLog(format string, args ...interface{})\n}\n",
+		ifaceInfo: iface.Interface{
+			Name:     "Logger",
+			MockName: "LoggerMock",
+			Methods: iface.Methods{
+				{Name: "Log", Params: iface.Params{{Name: "format", Type: "string"}, {Name: "args", Type: "[]interface{}", Variadic: true}}},
+			},
+		},
+		goVersion: "go1.17",
+		// The gomock style's mocks use its runtime library, which requires a
+		// newer Go version.
+		styles: slices.DeleteFunc(slices.Clone(Styles), func(style string) bool { return style == "gomock" }),
+	})
+}
+
+func TestDeclarations(t *testing.T) {
+	ifaceInfo := iface.Interface{
+		Name:     "Store",
+		MockName: "StoreMock",
+		Methods:  iface.Methods{{Name: "Get"}, {Name: "put"}},
+	}
+	type testCase struct {
+		style    string
+		expected []string
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			tmpl, loadErr := Load(testCase.style, "")
+			expect.ErrorNil(t, loadErr)
+			actual, declErr := tmpl.Declarations(ifaceInfo)
+			expect.ErrorNil(t, declErr)
+			expect.Equal(t, actual, testCase.expected)
+		})
+	}
+
+	run("Minimal", testCase{
+		style:    "minimal",
+		expected: []string{"StoreMock"},
+	})
+	run("Recorder", testCase{
+		style:    "recorder",
+		expected: []string{"StoreMock", "StoreMockGetCall", "StoreMockPutCall"},
+	})
+	run("Fluent", testCase{
+		style:    "fluent",
+		expected: []string{"StoreMock", "NewStoreMock", "StoreMockGetExpectation", "StoreMockPutExpectation"},
+	})
+	run("Moq", testCase{
+		style:    "moq",
+		expected: []string{"StoreMock"},
+	})
+	run("Gomock", testCase{
+		style:    "gomock",
+		expected: []string{"StoreMock", "StoreMockMockRecorder", "NewStoreMock"},
+	})
+}
+
+func TestMembers(t *testing.T) {
+	type testCase struct {
+		style         string
+		methods       []string
+		expectedError string
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			tmpl, loadErr := Load(testCase.style, "")
+			expect.ErrorNil(t, loadErr)
+			ifaceInfo := iface.Interface{Name: "Store", MockName: "StoreMock"}
+			for _, method := range testCase.methods {
+				ifaceInfo.Methods = append(ifaceInfo.Methods, iface.Method{Name: method})
+			}
+			file := iface.File{Package: "store", PackagePath: "example.com/store", Interfaces: []iface.Interface{ifaceInfo}}
+			_, renderErr := tmpl.Render("/store/store_mock.go", file)
+			if testCase.expectedError == "" {
+				expect.ErrorNil(t, renderErr)
+				return
+			}
+			expect.ErrorNonNil(t, renderErr)
+			expect.Equal(t, renderErr.Error(), testCase.expectedError)
+		})
+	}
+
+	run("Moq", testCase{
+		style:   "moq",
+		methods: []string{"Get", "Put"},
+	})
+	run("Moq/Calls", testCase{
+		style:         "moq",
+		methods:       []string{"Get", "GetCalls"},
+		expectedError: "GetCalls, declared by mock StoreMock of Store in /store/store_mock.go, collides with method Store.GetCalls; choose another style with the -style option",
+	})
+	run("Moq/Func", testCase{
+		style:         "moq",
+		methods:       []string{"GetFunc", "Get"},
+		expectedError: "GetFunc, declared by mock StoreMock of Store in /store/store_mock.go, collides with method Store.GetFunc; choose another style with the -style option",
+	})
+	run("Minimal/Stub", testCase{
+		style:         "minimal",
+		methods:       []string{"Get", "GetStub"},
+		expectedError: "GetStub, declared by mock StoreMock of Store in /store/store_mock.go, collides with method Store.GetStub; choose another style with the -style option",
+	})
+	run("Recorder", testCase{
+		style:   "recorder",
+		methods: []string{"Get", "Put"},
+	})
+	run("Recorder/Calls", testCase{
+		style:         "recorder",
+		methods:       []string{"Get", "GetCalls"},
+		expectedError: "GetCalls, declared by mock StoreMock of Store in /store/store_mock.go, collides with method Store.GetCalls; choose another style with the -style option",
+	})
+	run("Fluent/Expect", testCase{
+		style:         "fluent",
+		methods:       []string{"Get", "ExpectGet"},
+		expectedError: "ExpectGet, declared by mock StoreMock of Store in /store/store_mock.go, collides with method Store.ExpectGet; choose another style with the -style option",
+	})
+	run("Gomock/Expect", testCase{
+		style:         "gomock",
+		methods:       []string{"EXPECT"},
+		expectedError: "EXPECT, declared by mock StoreMock of Store in /store/store_mock.go, collides with method Store.EXPECT; choose another style with the -style option",
+	})
+}
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"context"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nicheinc/mock/internal/configfile"
+	"github.com/nicheinc/mock/render"
+	"golang.org/x/tools/go/packages"
+)
+
+// watchInterval is how often watch mode polls the watched files for changes.
+const watchInterval = 500 * time.Millisecond
+
+// fileStamp identifies a version of a watched file or directory. The zero value
+// represents a path that doesn't exist.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// stat returns the stamp of the file or directory at the given path.
+func stat(path string) fileStamp {
+	info, statErr := os.Stat(path)
+	if statErr != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}
+}
+
+// watcher regenerates the mocks of the packages matching a pattern as their
+// files, or the files of their dependencies in the main module, change.
+type watcher struct {
+	config config
+
+	// Loaded packages, including test variants, keyed by ID
+	pkgs map[string]*packages.Package
+	// Stamps of the watched files and directories, keyed by path
+	stamps map[string]fileStamp
+	// Import paths of the matching packages affected by each watched path
+	dependents map[string][]string
+	// Import paths of the matching packages
+	roots map[string]bool
+	// Paths of the watched configuration files
+	configFiles map[string]bool
+}
+
+// watchPackages generates the mocks of the packages matching the -watch
+// pattern, then polls the packages' files, regenerating the mocks of the
+// packages affected by each change. It prints errors rather than exiting, and
+// runs until interrupted.
+func (c config) watchPackages() {
+	w := &watcher{
+		config: c,
+		pkgs:   map[string]*packages.Package{},
+	}
+	log.Printf("Watching %s for changes", c.watch)
+	if graphErr := w.refreshGraph(); graphErr != nil {
+		log.Printf("Error listing packages: %s", graphErr)
+	}
+	w.regenerate(nil)
+	for {
+		time.Sleep(watchInterval)
+		changed := w.poll()
+		if len(changed) == 0 {
+			continue
+		}
+
+		affected, all, graphErr := w.affected(changed)
+		if graphErr != nil {
+			log.Printf("Error listing packages: %s", graphErr)
+			continue
+		}
+		if all {
+			w.regenerate(nil)
+		} else if len(affected) > 0 {
+			w.regenerate(affected)
+		}
+	}
+}
+
+// affected refreshes the package graph after changes to the given watched
+// paths, returning the import paths of the matching packages affected by them,
+// or whether all of them are.
+func (w *watcher) affected(changed []string) ([]string, bool, error) {
+	// Packages are affected by changes to their own files or those of their
+	// dependencies, including files since removed. Packages that weren't
+	// previously watched, e.g. in new directories, are affected too.
+	var (
+		affected      = map[string]bool{}
+		configChanged = false
+		oldRoots      = w.roots
+	)
+	for _, path := range changed {
+		for _, root := range w.dependents[path] {
+			affected[root] = true
+		}
+		configChanged = configChanged || w.configFiles[path]
+	}
+	if graphErr := w.refreshGraph(); graphErr != nil {
+		return nil, false, graphErr
+	}
+	for _, path := range changed {
+		for _, root := range w.dependents[path] {
+			affected[root] = true
+		}
+	}
+	for root := range w.roots {
+		if !oldRoots[root] {
+			affected[root] = true
+		}
+	}
+
+	// A change to a configuration file may affect any package.
+	if configChanged {
+		return nil, true, nil
+	}
+	return slices.Sorted(maps.Keys(affected)), false, nil
+}
+
+// poll returns the watched paths whose stamps have changed since the last poll,
+// updating their stamps.
+func (w *watcher) poll() []string {
+	var changed []string
+	for path, stamp := range w.stamps {
+		if newStamp := stat(path); newStamp != stamp {
+			w.stamps[path] = newStamp
+			changed = append(changed, path)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
+
+// refreshGraph lists the packages matching the pattern along with their
+// dependencies, without type-checking them, and starts watching the files and
+// directories of those in main modules, along with the modules' configuration
+// files. Directories between a module's root and its packages are watched too,
+// so that new packages are noticed.
+func (w *watcher) refreshGraph() error {
+	graphPkgs, listErr := packages.Load(&packages.Config{
+		Mode:  packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedModule,
+		Tests: true,
+	}, w.config.watch)
+	if listErr != nil {
+		return listErr
+	}
+
+	var (
+		dependents  = map[string][]string{}
+		roots       = map[string]bool{}
+		configFiles = map[string]bool{}
+		dirs        = map[string]bool{}
+	)
+	addDependent := func(path, root string) {
+		if !slices.Contains(dependents[path], root) {
+			dependents[path] = append(dependents[path], root)
+		}
+	}
+	for _, rootPkg := range graphPkgs {
+		if strings.HasSuffix(rootPkg.ID, ".test") {
+			continue
+		}
+		root := basePath(rootPkg)
+		roots[root] = true
+
+		visited := map[*packages.Package]bool{}
+		var visit func(pkg *packages.Package)
+		visit = func(pkg *packages.Package) {
+			if visited[pkg] {
+				return
+			}
+			visited[pkg] = true
+			if pkg.Module == nil || !pkg.Module.Main {
+				return
+			}
+			for _, path := range slices.Concat(pkg.GoFiles, pkg.OtherFiles, pkg.IgnoredFiles) {
+				addDependent(path, root)
+			}
+			// Files added to the package's directory belong to it, while new
+			// directories above it may contain new packages.
+			if pkg.Dir != "" {
+				addDependent(pkg.Dir, root)
+				for dir := pkg.Dir; dir != pkg.Module.Dir; {
+					rel, relErr := filepath.Rel(pkg.Module.Dir, dir)
+					if relErr != nil || !filepath.IsLocal(rel) {
+						break
+					}
+					dir = filepath.Dir(dir)
+					dirs[dir] = true
+				}
+			}
+			configFiles[filepath.Join(pkg.Module.Dir, configfile.Name)] = true
+			for _, imported := range pkg.Imports {
+				visit(imported)
+			}
+		}
+		visit(rootPkg)
+	}
+	// Changes to the custom template given on the command line affect every
+	// package, like changes to configuration files.
+	if w.config.template != "" {
+		configFiles[w.config.template] = true
+	}
+
+	// Keep the stamps of paths that were already watched, so that changes
+	// made since the last poll aren't missed.
+	stamps := map[string]fileStamp{}
+	for path := range dependents {
+		stamps[path] = w.stamp(path)
+	}
+	for path := range configFiles {
+		stamps[path] = w.stamp(path)
+	}
+	for path := range dirs {
+		stamps[path] = w.stamp(path)
+	}
+	w.stamps, w.dependents, w.roots, w.configFiles = stamps, dependents, roots, configFiles
+	return nil
+}
+
+// stamp returns the watched path's last stamp, or its current one if it wasn't
+// already watched.
+func (w *watcher) stamp(path string) fileStamp {
+	if stamp, watched := w.stamps[path]; watched {
+		return stamp
+	}
+	return stat(path)
+}
+
+// regenerate reloads the packages with the given import paths, or all the
+// packages matching the pattern if nil, then regenerates their mocks, printing
+// any errors.
+func (w *watcher) regenerate(affected []string) {
+	patterns := affected
+	if affected == nil {
+		patterns = []string{w.config.watch}
+	}
+	loaded, loadErr := w.config.generator(patterns...).LoadPackages(context.Background())
+	if loadErr != nil {
+		log.Printf("Error loading packages: %s", loadErr)
+		return
+	}
+
+	// Rather than generate broken mocks from packages that don't parse, e.g.
+	// while they're being edited, wait for the errors to be fixed. Type errors
+	// are tolerated, since stale mocks themselves cause them.
+	var pkgErrs []string
+	for _, pkg := range loaded {
+		for _, pkgErr := range pkg.Errors {
+			if pkgErr.Kind != packages.TypeError {
+				pkgErrs = append(pkgErrs, pkgErr.Error())
+			}
+		}
+	}
+	if len(pkgErrs) > 0 {
+		slices.Sort(pkgErrs)
+		for _, pkgErr := range slices.Compact(pkgErrs) {
+			log.Printf("Error: %s", pkgErr)
+		}
+		return
+	}
+
+	// Replace the affected packages, including those that no longer exist.
+	for id, pkg := range w.pkgs {
+		if affected == nil || slices.Contains(affected, basePath(pkg)) {
+			delete(w.pkgs, id)
+		}
+	}
+	for _, pkg := range loaded {
+		w.pkgs[pkg.ID] = pkg
+	}
+	pkgs := slices.SortedFunc(maps.Values(w.pkgs), func(a, b *packages.Package) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	// Custom templates may have been edited since they were loaded.
+	w.config.templates = render.NewCache()
+
+	// Only the affected packages' mocks are regenerated, but they mustn't
+	// collide with declarations in the others.
+	filesByPath, orphaned, getErr := w.config.getAllInterfaces(pkgs, loaded)
+	if getErr != nil {
+		logErrors("Error finding mocks", getErr)
+		return
+	}
+	rendered, renderErr := w.config.generator().Render(context.Background(), filesByPath)
+	if renderErr != nil {
+		logErrors("Error rendering mocks", renderErr)
+		return
+	}
+	stale, missing, staleErr := staleFiles(rendered)
+	if staleErr != nil {
+		log.Printf("Error checking mock files: %s", staleErr)
+		return
+	}
+	changed := slices.Sorted(slices.Values(slices.Concat(stale, missing)))
+	writeErr := writeFiles(rendered, changed, orphaned)
+
+	// Don't let the tool's own changes trigger another regeneration.
+	for _, path := range slices.Concat(changed, orphaned) {
+		if _, watched := w.stamps[path]; watched {
+			w.stamps[path] = stat(path)
+		}
+		if _, watched := w.stamps[filepath.Dir(path)]; watched {
+			w.stamps[filepath.Dir(path)] = stat(filepath.Dir(path))
+		}
+	}
+	if writeErr != nil {
+		logErrors("Error writing mocks", writeErr)
+		return
+	}
+	w.config.printSummary(len(changed), len(rendered)-len(changed), len(orphaned))
+}
+
+// basePath returns the import path of the package that the given package is,
+// or is a test variant or external test package of.
+func basePath(pkg *packages.Package) string {
+	return strings.TrimSuffix(pkg.PkgPath, "_test")
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nicheinc/expect"
+	"github.com/nicheinc/mock/internal/testmodule"
+)
+
+// writeFile writes the given contents to the file at the given path, creating
+// its directory, and moves its modification time forward so that the change is
+// noticed even on file systems with coarse timestamps.
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+	testmodule.WriteFile(t, path, contents)
+	modTime := time.Now().Add(time.Duration(len(contents)+1) * time.Minute)
+	if chtimesErr := os.Chtimes(path, modTime, modTime); chtimesErr != nil {
+		t.Fatal(chtimesErr)
+	}
+}
+
+// writeWatchedModule writes a module in which package a imports package b,
+// package c is nested in another directory, and package a has in-package and
+// external tests. It changes to the module's directory and returns it.
+func writeWatchedModule(t *testing.T) string {
+	t.Helper()
+	dir := testmodule.Write(t, map[string]string{
+		"a/a.go":           "package a\n\nimport _ \"example.com/m/b\"\n",
+		"a/a_test.go":      "package a\n",
+		"a/ext_test.go":    "package a_test\n\nimport _ \"example.com/m/a\"\n",
+		"b/b.go":           "package b\n",
+		"nested/c/c.go":    "package c\n",
+		"templates/x.tmpl": "",
+	})
+	t.Chdir(dir)
+	return dir
+}
+
+func TestPoll(t *testing.T) {
+	dir := t.TempDir()
+	var (
+		changedPath   = filepath.Join(dir, "changed.go")
+		removedPath   = filepath.Join(dir, "removed.go")
+		newPath       = filepath.Join(dir, "new.go")
+		unchangedPath = filepath.Join(dir, "unchanged.go")
+	)
+	for _, path := range []string{changedPath, removedPath, unchangedPath} {
+		writeFile(t, path, "package p\n")
+	}
+	w := &watcher{stamps: map[string]fileStamp{
+		changedPath:   stat(changedPath),
+		removedPath:   stat(removedPath),
+		newPath:       stat(newPath),
+		unchangedPath: stat(unchangedPath),
+	}}
+	expect.DeepEqual(t, w.stamps[newPath], fileStamp{})
+
+	writeFile(t, changedPath, "package p\n\nvar x int\n")
+	if removeErr := os.Remove(removedPath); removeErr != nil {
+		t.Fatal(removeErr)
+	}
+	writeFile(t, newPath, "package p\n")
+
+	changed := w.poll()
+	expect.Equal(t, changed, []string{changedPath, newPath, removedPath})
+	expect.DeepEqual(t, w.stamps, map[string]fileStamp{
+		changedPath:   stat(changedPath),
+		removedPath:   {},
+		newPath:       stat(newPath),
+		unchangedPath: stat(unchangedPath),
+	})
+
+	// Stamps are updated, so the changes are only reported once.
+	expect.Equal(t, len(w.poll()), 0)
+}
+
+func TestRefreshGraph(t *testing.T) {
+	dir := writeWatchedModule(t)
+	template := filepath.Join(dir, "templates", "x.tmpl")
+	w := &watcher{config: config{watch: "./...", template: template}}
+	if graphErr := w.refreshGraph(); graphErr != nil {
+		t.Fatal(graphErr)
+	}
+
+	dependents := map[string][]string{}
+	for path, roots := range w.dependents {
+		dependents[path] = slices.Sorted(slices.Values(roots))
+	}
+	expect.Equal(t, dependents, map[string][]string{
+		// Packages depend on their own files and directories, including those
+		// of their tests, and on those of their dependencies in the module.
+		filepath.Join(dir, "a", "a.go"):           {"example.com/m/a"},
+		filepath.Join(dir, "a", "a_test.go"):      {"example.com/m/a"},
+		filepath.Join(dir, "a", "ext_test.go"):    {"example.com/m/a"},
+		filepath.Join(dir, "a"):                   {"example.com/m/a"},
+		filepath.Join(dir, "b", "b.go"):           {"example.com/m/a", "example.com/m/b"},
+		filepath.Join(dir, "b"):                   {"example.com/m/a", "example.com/m/b"},
+		filepath.Join(dir, "nested", "c", "c.go"): {"example.com/m/nested/c"},
+		filepath.Join(dir, "nested", "c"):         {"example.com/m/nested/c"},
+	})
+	expect.Equal(t, w.roots, map[string]bool{
+		"example.com/m/a":        true,
+		"example.com/m/b":        true,
+		"example.com/m/nested/c": true,
+	})
+	expect.Equal(t, w.configFiles, map[string]bool{
+		filepath.Join(dir, "mock.json"): true,
+		template:                        true,
+	})
+
+	// Every dependent path and configuration file is watched, along with the
+	// directories between the module's root and its packages, in which new
+	// packages may appear.
+	var watched []string
+	for path := range w.stamps {
+		watched = append(watched, path)
+	}
+	slices.Sort(watched)
+	expect.Equal(t, watched, []string{
+		dir,
+		filepath.Join(dir, "a"),
+		filepath.Join(dir, "a", "a.go"),
+		filepath.Join(dir, "a", "a_test.go"),
+		filepath.Join(dir, "a", "ext_test.go"),
+		filepath.Join(dir, "b"),
+		filepath.Join(dir, "b", "b.go"),
+		filepath.Join(dir, "mock.json"),
+		filepath.Join(dir, "nested"),
+		filepath.Join(dir, "nested", "c"),
+		filepath.Join(dir, "nested", "c", "c.go"),
+		template,
+	})
+}
+
+func TestAffected(t *testing.T) {
+	dir := writeWatchedModule(t)
+	template := filepath.Join(dir, "templates", "x.tmpl")
+
+	type testCase struct {
+		change           func(t *testing.T)
+		expectedAffected []string
+		expectedAll      bool
+	}
+	run := func(name string, testCase testCase) {
+		t.Helper()
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			w := &watcher{config: config{watch: "./...", template: template}}
+			if graphErr := w.refreshGraph(); graphErr != nil {
+				t.Fatal(graphErr)
+			}
+			testCase.change(t)
+			affected, all, err := w.affected(w.poll())
+			expect.ErrorNil(t, err)
+			expect.Equal(t, affected, testCase.expectedAffected)
+			expect.Equal(t, all, testCase.expectedAll)
+		})
+	}
+
+	run("Dependency", testCase{
+		change: func(t *testing.T) {
+			writeFile(t, filepath.Join(dir, "b", "b.go"), "package b\n\nvar B int\n")
+		},
+		expectedAffected: []string{"example.com/m/a", "example.com/m/b"},
+	})
+	run("TestFile", testCase{
+		change: func(t *testing.T) {
+			writeFile(t, filepath.Join(dir, "a", "a_test.go"), "package a\n\nvar A int\n")
+		},
+		expectedAffected: []string{"example.com/m/a"},
+	})
+	run("NewPackage", testCase{
+		change: func(t *testing.T) {
+			writeFile(t, filepath.Join(dir, "nested", "d", "d.go"), "package d\n")
+		},
+		expectedAffected: []string{"example.com/m/nested/d"},
+	})
+	run("ConfigFile", testCase{
+		change: func(t *testing.T) {
+			writeFile(t, filepath.Join(dir, "mock.json"), "{}\n")
+			t.Cleanup(func() { os.Remove(filepath.Join(dir, "mock.json")) })
+		},
+		expectedAll: true,
+	})
+	run("Template", testCase{
+		change: func(t *testing.T) {
+			writeFile(t, template, "{{ define \"mock\" }}{{ end }}\n")
+		},
+		expectedAll: true,
+	})
+}